@@ -1,213 +1,117 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"io"
 	"log"
+	"os"
+	"path/filepath"
 
-	"github.com/blkmlk/microshell/internal/logger"
-
-	"github.com/blkmlk/microshell/internal/parser"
-
-	"github.com/blkmlk/microshell/internal/cursor"
-	"github.com/blkmlk/microshell/internal/history"
-	"github.com/blkmlk/microshell/internal/prompt"
-
-	"github.com/blkmlk/microshell/internal/shell"
-	"github.com/blkmlk/microshell/internal/terminal"
-	"github.com/sarulabs/di/v2"
+	"github.com/blkmlk/microshell"
 )
 
 func main() {
-	builder, err := di.NewBuilder()
-
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	listDefinition := di.Def{
-		Name: parser.DefinitionNameCommandTree,
-		Build: func(ctn di.Container) (interface{}, error) {
-			return parser.List{Commands: []*parser.Command{
-				{
-					Type: parser.CommandTypeUser,
-					Path: []string{"ip", "firewall"},
-					Name: "add",
-					Flags: map[string]*parser.Flag{
-						"network": {
-							Name:      "network",
-							Mandatory: true,
-							Number:    1,
-							ValueType: parser.ValueTypeString,
-						},
-						"protocol": {
-							Name:      "protocol",
-							Mandatory: true,
-							Number:    2,
-							ValueType: parser.ValueTypeString,
-						},
-						"port": {
-							Name:      "port",
-							Mandatory: true,
-							Number:    3,
-							ValueType: parser.ValueTypeString,
-						},
-					},
-					Options: map[string]bool{
-						"verbose": false,
-					},
-				},
-				{
-					Type:           parser.CommandTypeSystem,
-					Path:           nil,
-					Name:           "global",
-					SystemExecFunc: setGlobalVariable,
-					OutFunc:        outGlobalVariable,
-					Flags: map[string]*parser.Flag{
-						"name": {
-							Name:      "name",
-							Mandatory: true,
-							Number:    1,
-							ValueType: parser.ValueTypeString,
-						},
-						"value": {
-							Name:      "value",
-							Mandatory: true,
-							Number:    2,
-							ValueType: parser.ValueTypeString,
-						},
-					},
+	file := flag.String("f", "", "execute the script `file` and exit, - reads the script from the standard input")
+	command := flag.String("c", "", "execute the `commands` and exit")
+	listen := flag.String("listen", "", "serve the shell over telnet on `address`")
+	historyFile := flag.String("history", defaultHistoryFile(), "keep the history in `file`, an empty value disables it")
+	flag.Parse()
+
+	builder := microshell.NewBuilder().
+		AddCommands(&microshell.Command{
+			Type: microshell.CommandTypeUser,
+			Path: []string{"ip", "firewall"},
+			Name: "add",
+			Flags: map[string]*microshell.Flag{
+				"network": {
+					Name:      "network",
+					Mandatory: true,
+					Number:    1,
+					ValueType: microshell.ValueTypeString,
 				},
-				{
-					Type:           parser.CommandTypeSystem,
-					Path:           nil,
-					Name:           "local",
-					SystemExecFunc: setLocalVariable,
-					OutFunc:        outLocalVariable,
-					Flags: map[string]*parser.Flag{
-						"name": {
-							Name:      "name",
-							Mandatory: true,
-							Number:    1,
-							ValueType: parser.ValueTypeString,
-						},
-						"value": {
-							Name:      "value",
-							Mandatory: true,
-							Number:    2,
-							ValueType: parser.ValueTypeString,
-						},
-					},
+				"protocol": {
+					Name:      "protocol",
+					Mandatory: true,
+					Number:    2,
+					ValueType: microshell.ValueTypeString,
 				},
-				{
-					Type:           parser.CommandTypeSystem,
-					Path:           nil,
-					Name:           "put",
-					SystemExecFunc: putValue,
-					OutFunc:        nil,
-					Flags: map[string]*parser.Flag{
-						"value": {
-							Name:      "value",
-							Mandatory: true,
-							Number:    1,
-							ValueType: parser.ValueTypeString,
-						},
-					},
+				"port": {
+					Name:      "port",
+					Mandatory: true,
+					Number:    3,
+					ValueType: microshell.ValueTypeString,
 				},
-			}}, nil
-		},
+			},
+			Options: map[string]bool{
+				"verbose": false,
+			},
+		}).
+		SetPrompt("localhost", "void")
+
+	if *file != "" || *command != "" {
+		if err := runScript(builder, *file, *command); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		return
 	}
 
-	err = builder.Add(
-		terminal.Definition,
-		cursor.Definition,
-		prompt.Definition,
-		history.Definition,
-		logger.Definition,
-		terminal.DefinitionBuffer,
+	if *listen != "" {
+		srv, err := builder.BuildServer()
+		if err != nil {
+			log.Fatal(err)
+		}
 
-		parser.Definition,
-		parser.DefinitionScope,
-		parser.DefinitionContext,
-		listDefinition,
-	)
+		log.Fatal(srv.ListenAndServe(*listen))
+	}
+
+	sh, err := builder.
+		SetHistoryOptions(microshell.HistoryOptions{
+			File:         *historyFile,
+			Size:         1000,
+			IgnoreSpace:  true,
+			PrefixSearch: true,
+		}).
+		Build()
 
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	ctn := builder.Build()
-
-	sh := shell.NewShell(ctn)
-	sh.SetColors(map[parser.Object]terminal.Color{
-		parser.ObjectError:             terminal.ColorRed,
-		parser.ObjectPath:              terminal.ColorBlue,
-		parser.ObjectCommand:           terminal.ColorBlue,
-		parser.ObjectOptionalFlag:      terminal.ColorYellow,
-		parser.ObjectMandatoryFlag:     terminal.ColorYellow,
-		parser.ObjectOption:            terminal.ColorMagenta,
-		parser.ObjectValue:             terminal.ColorWhite,
-		parser.ObjectEqualSymbol:       terminal.ColorCyan,
-		parser.ObjectCurlyBrackets:     terminal.ColorYellow,
-		parser.ObjectRoundBrackets:     terminal.ColorYellow,
-		parser.ObjectSquareBrackets:    terminal.ColorYellow,
-		parser.ObjectOperator:          terminal.ColorYellow,
-		parser.ObjectQuotedSymbol:      terminal.ColorCyan,
-		parser.ObjectQuotedString:      terminal.ColorCyan,
-		parser.ObjectVariableSymbol:    terminal.ColorBlue,
-		parser.ObjectVariableName:      terminal.ColorBlue,
-		parser.ObjectVariableWrongName: terminal.ColorRed,
-	})
-
 	sh.Run()
 }
 
-func setGlobalVariable(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
-	name := flags.Get("name").Value(ctx).String()
-
-	e := flags.Get("value").Expression()
-
-	if e.Type() == parser.ExpressionTypeCmdList {
-		ctx.SetGlobalVariable(name, e)
-	} else {
-		ctx.SetGlobalVariable(name, e.Value(ctx))
+func defaultHistoryFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
 	}
 
-	ctx.Logger().WriteMessages("Set", name, e.Value(ctx).String())
-
-	return nil, nil
+	return filepath.Join(home, ".microshell_history")
 }
 
-func setLocalVariable(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
-	name := flags.Get("name").Value(ctx).String()
-
-	e := flags.Get("value").Expression()
-
-	if e.Type() == parser.ExpressionTypeCmdList {
-		ctx.SetLocalVariable(name, e)
-	} else {
-		ctx.SetLocalVariable(name, e.Value(ctx))
+func runScript(builder *microshell.Builder, file, command string) error {
+	runner, err := builder.BuildRunner(os.Stdout)
+	if err != nil {
+		return err
 	}
 
-	return nil, nil
-}
-
-func outLocalVariable(ctx parser.SystemContext, flags parser.Flags, options parser.Options) {
-	nameFlag := flags.Get("name")
-
-	if nameFlag != nil && !ctx.VariableExists(nameFlag.Name) {
-		ctx.SetLocalVariable(nameFlag.Value(ctx).String(), parser.NullValue)
+	if command != "" {
+		return runner.RunString(command)
 	}
-}
 
-func outGlobalVariable(ctx parser.SystemContext, flags parser.Flags, options parser.Options) {
-	nameFlag := flags.Get("name")
+	var in io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
 
-	if nameFlag != nil && !ctx.VariableExists(nameFlag.Name) {
-		ctx.SetGlobalVariable(nameFlag.Value(ctx).String(), parser.NullValue)
+		defer f.Close()
+		in = f
 	}
-}
 
-func putValue(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
-	valueFlag := flags.Get("value")
-	ctx.Buffer().Push(terminal.NewPlainText(valueFlag.Value(ctx).String()))
-	return parser.NullValue, nil
+	return runner.Run(file, in)
 }
@@ -7,4 +7,5 @@ require (
 	github.com/mattn/go-runewidth v0.0.13
 	github.com/sarulabs/di/v2 v2.4.2
 	github.com/stretchr/testify v1.7.0
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c
 )
@@ -0,0 +1,201 @@
+package builtin
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/blkmlk/microshell/internal/parser"
+)
+
+func arrayCommands() []*parser.Command {
+	return []*parser.Command{
+		{
+			Type:           parser.CommandTypeSystem,
+			Name:           "len",
+			Description:    "returns the number of the elements of an array or the characters of a string",
+			SystemExecFunc: execLen,
+			Flags: map[string]*parser.Flag{
+				"value": {
+					Name:      "value",
+					Mandatory: true,
+					Number:    1,
+					ValueType: parser.ValueTypeString,
+				},
+			},
+		},
+		{
+			Type:           parser.CommandTypeSystem,
+			Name:           "pick",
+			Description:    "returns the elements or the characters from begin up to end",
+			SystemExecFunc: execPick,
+			Flags: map[string]*parser.Flag{
+				"value": {
+					Name:      "value",
+					Mandatory: true,
+					Number:    1,
+					ValueType: parser.ValueTypeString,
+				},
+				"begin": {
+					Name:      "begin",
+					Mandatory: true,
+					Number:    2,
+					ValueType: parser.ValueTypeNumber,
+				},
+				"end": {
+					Name:      "end",
+					Number:    3,
+					ValueType: parser.ValueTypeNumber,
+				},
+			},
+		},
+		{
+			Type:           parser.CommandTypeSystem,
+			Name:           "find",
+			Description:    "returns the position of the element or the substring",
+			SystemExecFunc: execFind,
+			Flags: map[string]*parser.Flag{
+				"value": {
+					Name:      "value",
+					Mandatory: true,
+					Number:    1,
+					ValueType: parser.ValueTypeString,
+				},
+				"needle": {
+					Name:      "needle",
+					Mandatory: true,
+					Number:    2,
+					ValueType: parser.ValueTypeString,
+				},
+				"start": {
+					Name:      "start",
+					Number:    3,
+					ValueType: parser.ValueTypeNumber,
+				},
+			},
+		},
+	}
+}
+
+func execLen(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	value, err := flags.Get("value").Value(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if array, ok := value.(parser.Array); ok {
+		return parser.NewNumberValue(array.Len()), nil
+	}
+
+	return parser.NewNumberValue(utf8.RuneCountInString(value.String())), nil
+}
+
+// execPick returns a single element when the end is left out.
+func execPick(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	value, err := flags.Get("value").Value(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	beginValue, err := flags.Get("begin").Value(ctx)
+	if err != nil {
+		return nil, err
+	}
+	begin := beginValue.Number()
+
+	end, single := begin+1, true
+	if f := flags.Get("end"); f != nil {
+		endValue, err := f.Value(ctx)
+		if err != nil {
+			return nil, err
+		}
+		end, single = endValue.Number(), false
+	}
+
+	if array, ok := value.(parser.Array); ok {
+		items := array.Items()
+		from, to := bounds(begin, end, len(items))
+
+		if single {
+			if from == to {
+				return parser.NullValue, nil
+			}
+
+			return items[from].Value, nil
+		}
+
+		return parser.NewArrayValueFromItems(items[from:to]), nil
+	}
+
+	runes := []rune(value.String())
+	from, to := bounds(begin, end, len(runes))
+
+	return parser.NewStringValue(string(runes[from:to])), nil
+}
+
+// execFind returns nil when there is no such element or substring.
+func execFind(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	value, err := flags.Get("value").Value(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	needle, err := flags.Get("needle").Value(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	start := 0
+	if f := flags.Get("start"); f != nil {
+		startValue, err := f.Value(ctx)
+		if err != nil {
+			return nil, err
+		}
+		start = startValue.Number()
+	}
+
+	if array, ok := value.(parser.Array); ok {
+		items := array.Items()
+
+		for i := start; i >= 0 && i < len(items); i++ {
+			if items[i].Value.Equal(needle) {
+				return parser.NewNumberValue(i), nil
+			}
+		}
+
+		return parser.NilValue, nil
+	}
+
+	runes := []rune(value.String())
+	if start < 0 || start > len(runes) {
+		return parser.NilValue, nil
+	}
+
+	i := strings.Index(string(runes[start:]), needle.String())
+	if i < 0 {
+		return parser.NilValue, nil
+	}
+
+	return parser.NewNumberValue(start + utf8.RuneCountInString(string(runes[start:])[:i])), nil
+}
+
+// bounds clamps the range to the length.
+func bounds(begin, end, length int) (int, int) {
+	begin, end = clamp(begin, length), clamp(end, length)
+
+	if begin > end {
+		return end, end
+	}
+
+	return begin, end
+}
+
+func clamp(n, length int) int {
+	switch {
+	case n < 0:
+		return 0
+	case n > length:
+		return length
+	}
+
+	return n
+}
@@ -0,0 +1,170 @@
+package builtin
+
+import (
+	"github.com/blkmlk/microshell/internal/parser"
+	"github.com/blkmlk/microshell/internal/terminal"
+)
+
+func Commands() []*parser.Command {
+	commands := []*parser.Command{
+		{
+			Type:           parser.CommandTypeSystem,
+			Path:           nil,
+			Name:           "global",
+			SystemExecFunc: setGlobalVariable,
+			OutFunc:        outGlobalVariable,
+			Flags: map[string]*parser.Flag{
+				"name": {
+					Name:      "name",
+					Mandatory: true,
+					Number:    1,
+					ValueType: parser.ValueTypeString,
+				},
+				"value": {
+					Name:      "value",
+					Mandatory: true,
+					Number:    2,
+					ValueType: parser.ValueTypeString,
+				},
+			},
+		},
+		{
+			Type:           parser.CommandTypeSystem,
+			Path:           nil,
+			Name:           "local",
+			SystemExecFunc: setLocalVariable,
+			OutFunc:        outLocalVariable,
+			Flags: map[string]*parser.Flag{
+				"name": {
+					Name:      "name",
+					Mandatory: true,
+					Number:    1,
+					ValueType: parser.ValueTypeString,
+				},
+				"value": {
+					Name:      "value",
+					Mandatory: true,
+					Number:    2,
+					ValueType: parser.ValueTypeString,
+				},
+			},
+		},
+		{
+			Type:           parser.CommandTypeSystem,
+			Path:           nil,
+			Name:           "put",
+			SystemExecFunc: putValue,
+			OutFunc:        nil,
+			Flags: map[string]*parser.Flag{
+				"value": {
+					Name:      "value",
+					Mandatory: true,
+					Number:    1,
+					ValueType: parser.ValueTypeString,
+				},
+			},
+		},
+	}
+
+	commands = append(commands, flowCommands()...)
+
+	commands = append(commands, arrayCommands()...)
+
+	commands = append(commands, scriptCommands()...)
+
+	commands = append(commands, schedulerCommands()...)
+
+	return append(commands, convertCommands()...)
+}
+
+func setGlobalVariable(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	name, err := flags.Get("name").Value(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	e := flags.Get("value").Expression()
+
+	if e.Type() == parser.ExpressionTypeCmdList {
+		ctx.SetGlobalVariable(name.String(), e)
+	} else {
+		value, err := e.Value(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		ctx.SetGlobalVariable(name.String(), value)
+	}
+
+	ctx.Logger().WriteMessages("Set", name.String())
+
+	return nil, nil
+}
+
+func setLocalVariable(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	name, err := flags.Get("name").Value(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	e := flags.Get("value").Expression()
+
+	if e.Type() == parser.ExpressionTypeCmdList {
+		ctx.SetLocalVariable(name.String(), e)
+	} else {
+		value, err := e.Value(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		ctx.SetLocalVariable(name.String(), value)
+	}
+
+	return nil, nil
+}
+
+func outLocalVariable(ctx parser.SystemContext, flags parser.Flags, options parser.Options) {
+	nameFlag := flags.Get("name")
+
+	if nameFlag == nil {
+		return
+	}
+
+	name, err := nameFlag.Value(ctx)
+	if err != nil {
+		return
+	}
+
+	// declares the variable for the following commands, a set one is kept
+	if !ctx.VariableExists(name.String()) {
+		ctx.SetLocalVariable(name.String(), parser.NullValue)
+	}
+}
+
+func outGlobalVariable(ctx parser.SystemContext, flags parser.Flags, options parser.Options) {
+	nameFlag := flags.Get("name")
+
+	if nameFlag == nil {
+		return
+	}
+
+	name, err := nameFlag.Value(ctx)
+	if err != nil {
+		return
+	}
+
+	// declares the variable for the following commands, a set one is kept
+	if !ctx.VariableExists(name.String()) {
+		ctx.SetGlobalVariable(name.String(), parser.NullValue)
+	}
+}
+
+func putValue(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	value, err := flags.Get("value").Value(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.Buffer().Push(terminal.NewPlainText(value.String()))
+	return parser.NullValue, nil
+}
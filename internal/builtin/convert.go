@@ -0,0 +1,124 @@
+package builtin
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/blkmlk/microshell/internal/parser"
+)
+
+func convertCommands() []*parser.Command {
+	return []*parser.Command{
+		valueCommand("typeof", "returns the type of the value", execTypeof),
+		valueCommand("tostr", "converts the value to a string", execToStr),
+		valueCommand("tonum", "converts the value to a number, nil if it isn't one", execToNum),
+		valueCommand("tobool", "converts the value to a bool, nil if it isn't one", execToBool),
+		valueCommand("toarray", "converts the value to an array, a string is split by commas", execToArray),
+	}
+}
+
+// valueCommand returns a command taking a single unnamed value.
+func valueCommand(name, description string, exec parser.SystemExecFunc) *parser.Command {
+	return &parser.Command{
+		Type:           parser.CommandTypeSystem,
+		Name:           name,
+		Description:    description,
+		SystemExecFunc: exec,
+		Flags: map[string]*parser.Flag{
+			"value": {
+				Name:      "value",
+				Mandatory: true,
+				Number:    1,
+				ValueType: parser.ValueTypeString,
+			},
+		},
+	}
+}
+
+func execTypeof(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	value, err := flags.Get("value").Value(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return parser.NewStringValue(string(value.Kind())), nil
+}
+
+func execToStr(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	value, err := flags.Get("value").Value(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return parser.NewStringValue(value.String()), nil
+}
+
+// execToNum takes an IPv4 address as an integer and a duration in seconds.
+func execToNum(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	value, err := flags.Get("value").Value(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch value.Kind() {
+	case parser.KindNumber:
+		return value, nil
+	case parser.KindIP, parser.KindTime:
+		return parser.NewNumberValue(value.Number()), nil
+	case parser.KindString:
+		if n, err := strconv.Atoi(strings.TrimSpace(value.String())); err == nil {
+			return parser.NewNumberValue(n), nil
+		}
+	}
+
+	return parser.NilValue, nil
+}
+
+// execToBool takes a number other than 0 as true, as well as "true" and "yes".
+func execToBool(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	value, err := flags.Get("value").Value(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch value.Kind() {
+	case parser.KindBool:
+		return value, nil
+	case parser.KindNumber:
+		return parser.NewBoolValue(value.Number() != 0), nil
+	case parser.KindString:
+		switch strings.TrimSpace(value.String()) {
+		case "true", "yes":
+			return parser.NewBoolValue(true), nil
+		case "false", "no":
+			return parser.NewBoolValue(false), nil
+		}
+	}
+
+	return parser.NilValue, nil
+}
+
+// execToArray reads the parts of a string as unquoted words, any other
+// single value becomes an array of one element.
+func execToArray(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	value, err := flags.Get("value").Value(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch value.Kind() {
+	case parser.KindArray:
+		return value, nil
+	case parser.KindNothing, parser.KindNil:
+		return parser.NewArrayValue(), nil
+	case parser.KindString:
+		var values []parser.Value
+		for _, part := range strings.Split(value.String(), ",") {
+			values = append(values, parser.ParseValue(strings.TrimSpace(part)))
+		}
+
+		return parser.NewArrayValue(values...), nil
+	}
+
+	return parser.NewArrayValue(value), nil
+}
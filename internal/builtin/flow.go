@@ -0,0 +1,368 @@
+package builtin
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/blkmlk/microshell/internal/parser"
+)
+
+func flowCommands() []*parser.Command {
+	return []*parser.Command{
+		{
+			Type:           parser.CommandTypeSystem,
+			Name:           "if",
+			Description:    "executes do when the condition is true, else otherwise",
+			SystemExecFunc: execIf,
+			Flags: map[string]*parser.Flag{
+				"condition": {
+					Name:      "condition",
+					Mandatory: true,
+					Number:    1,
+					ValueType: parser.ValueTypeBool,
+				},
+				"do": {
+					Name:      "do",
+					Mandatory: true,
+					ValueType: parser.ValueTypeString,
+				},
+				"else": {
+					Name:      "else",
+					ValueType: parser.ValueTypeString,
+				},
+			},
+		},
+		{
+			Type:           parser.CommandTypeSystem,
+			Name:           "while",
+			Description:    "executes do while the condition is true",
+			SystemExecFunc: execWhile,
+			Flags: map[string]*parser.Flag{
+				"condition": {
+					Name:      "condition",
+					Mandatory: true,
+					Number:    1,
+					ValueType: parser.ValueTypeBool,
+				},
+				"do": {
+					Name:      "do",
+					Mandatory: true,
+					ValueType: parser.ValueTypeString,
+				},
+			},
+		},
+		{
+			Type:           parser.CommandTypeSystem,
+			Name:           "for",
+			Description:    "executes do for every value of the counter from from to to",
+			SystemExecFunc: execFor,
+			Flags: map[string]*parser.Flag{
+				"counter": {
+					Name:      "counter",
+					Mandatory: true,
+					Number:    1,
+					ValueType: parser.ValueTypeString,
+				},
+				"from": {
+					Name:      "from",
+					Mandatory: true,
+					ValueType: parser.ValueTypeNumber,
+				},
+				"to": {
+					Name:      "to",
+					Mandatory: true,
+					ValueType: parser.ValueTypeNumber,
+				},
+				"step": {
+					Name:      "step",
+					ValueType: parser.ValueTypeNumber,
+				},
+				"do": {
+					Name:      "do",
+					Mandatory: true,
+					ValueType: parser.ValueTypeString,
+				},
+			},
+		},
+		{
+			Type:           parser.CommandTypeSystem,
+			Name:           "foreach",
+			Description:    "executes do for every item of in",
+			SystemExecFunc: execForeach,
+			Flags: map[string]*parser.Flag{
+				"counter": {
+					Name:      "counter",
+					Mandatory: true,
+					Number:    1,
+					ValueType: parser.ValueTypeString,
+				},
+				"in": {
+					Name:      "in",
+					Mandatory: true,
+					ValueType: parser.ValueTypeString,
+				},
+				"do": {
+					Name:      "do",
+					Mandatory: true,
+					ValueType: parser.ValueTypeString,
+				},
+			},
+		},
+		{
+			Type:           parser.CommandTypeSystem,
+			Name:           "do",
+			Description:    "executes the command, again while the condition is true, on-error if it fails",
+			SystemExecFunc: execDo,
+			Flags: map[string]*parser.Flag{
+				"command": {
+					Name:      "command",
+					Mandatory: true,
+					Number:    1,
+					ValueType: parser.ValueTypeString,
+				},
+				"while": {
+					Name:      "while",
+					ValueType: parser.ValueTypeBool,
+				},
+				"on-error": {
+					Name:      "on-error",
+					ValueType: parser.ValueTypeString,
+				},
+			},
+		},
+		{
+			Type:           parser.CommandTypeSystem,
+			Name:           "delay",
+			Description:    "waits for the time to pass",
+			SystemExecFunc: execDelay,
+			Flags: map[string]*parser.Flag{
+				"delay-time": {
+					Name:      "delay-time",
+					Mandatory: true,
+					Number:    1,
+					ValueType: parser.ValueTypeTime,
+				},
+			},
+		},
+		{
+			Type:           parser.CommandTypeSystem,
+			Name:           "error",
+			Description:    "fails with the message",
+			SystemExecFunc: execError,
+			Flags: map[string]*parser.Flag{
+				"message": {
+					Name:      "message",
+					Mandatory: true,
+					Number:    1,
+					ValueType: parser.ValueTypeString,
+				},
+			},
+		},
+	}
+}
+
+var errZeroStep = errors.New("step can't be zero")
+
+func execIf(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	condition, err := flags.Get("condition").Value(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if condition.Bool() {
+		return run(ctx.New(), flags.Get("do"))
+	}
+
+	if e := flags.Get("else"); e != nil {
+		return run(ctx.New(), e)
+	}
+
+	return parser.NullValue, nil
+}
+
+func execWhile(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	for {
+		condition, err := flags.Get("condition").Value(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if !condition.Bool() {
+			return parser.NullValue, nil
+		}
+
+		if _, err := run(ctx.New(), flags.Get("do")); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func execFor(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	name, err := flags.Get("counter").Value(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := number(ctx, flags.Get("from"))
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := number(ctx, flags.Get("to"))
+	if err != nil {
+		return nil, err
+	}
+
+	step := 1
+	if from > to {
+		step = -1
+	}
+
+	if f := flags.Get("step"); f != nil {
+		if step, err = number(ctx, f); err != nil {
+			return nil, err
+		}
+	}
+
+	if step == 0 {
+		return nil, errZeroStep
+	}
+
+	for i := from; (step > 0 && i <= to) || (step < 0 && i >= to); i += step {
+		scope := ctx.New()
+		scope.SetLocalVariable(name.String(), parser.NewNumberValue(i))
+
+		if _, err := run(scope, flags.Get("do")); err != nil {
+			return nil, err
+		}
+	}
+
+	return parser.NullValue, nil
+}
+
+func execForeach(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	name, err := flags.Get("counter").Value(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	in, err := flags.Get("in").Value(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items(in) {
+		scope := ctx.New()
+		scope.SetLocalVariable(name.String(), item)
+
+		if _, err := run(scope, flags.Get("do")); err != nil {
+			return nil, err
+		}
+	}
+
+	return parser.NullValue, nil
+}
+
+// execDo runs on-error with the message in $error if the command fails, a
+// cancelled execution isn't caught.
+func execDo(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	for {
+		value, err := run(ctx.New(), flags.Get("command"))
+		if err != nil {
+			onError := flags.Get("on-error")
+			if onError == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				return nil, err
+			}
+
+			scope := ctx.New()
+			scope.SetLocalVariable("error", parser.NewStringValue(err.Error()))
+
+			return run(scope, onError)
+		}
+
+		f := flags.Get("while")
+		if f == nil {
+			return value, nil
+		}
+
+		condition, err := f.Value(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if !condition.Bool() {
+			return value, nil
+		}
+	}
+}
+
+func execError(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	message, err := flags.Get("message").Value(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, &parser.UserError{Message: message.String()}
+}
+
+// execDelay returns early if the execution is cancelled.
+func execDelay(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	value, err := flags.Get("delay-time").Value(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	d, ok := parser.Duration(value)
+	if !ok {
+		return nil, parser.ErrWrongType
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timer.C:
+		return parser.NullValue, nil
+	}
+}
+
+// run evaluates the body of a command in the scope unless the execution has
+// been cancelled.
+func run(scope parser.SystemContext, body *parser.Flag) (parser.Value, error) {
+	if err := scope.Err(); err != nil {
+		return nil, err
+	}
+
+	return body.Value(scope)
+}
+
+func number(ctx parser.SystemContext, flag *parser.Flag) (int, error) {
+	value, err := flag.Value(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return value.Number(), nil
+}
+
+// items returns the values a loop walks through, a single value is a list of
+// one item.
+func items(value parser.Value) []parser.Value {
+	if array, ok := value.(parser.Array); ok {
+		var values []parser.Value
+		for _, item := range array.Items() {
+			values = append(values, item.Value)
+		}
+
+		return values
+	}
+
+	if value.String() == "" {
+		return nil
+	}
+
+	return []parser.Value{value}
+}
@@ -0,0 +1,243 @@
+package builtin
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/blkmlk/microshell/internal/parser"
+	"github.com/blkmlk/microshell/internal/terminal"
+)
+
+// timeLayout is the form the times of the tasks, the scripts and the jobs
+// are printed in.
+const timeLayout = "2006-01-02 15:04:05"
+
+var (
+	schedulerPath = []string{"system", "scheduler"}
+
+	errNoSchedule = errors.New("interval or start-time is required")
+)
+
+func schedulerCommands() []*parser.Command {
+	return []*parser.Command{
+		{
+			Type:           parser.CommandTypeSystem,
+			Path:           schedulerPath,
+			Name:           "add",
+			Description:    "runs on-event at start-time and then every interval",
+			SystemExecFunc: execSchedulerAdd,
+			Flags: map[string]*parser.Flag{
+				"name": {
+					Name:      "name",
+					Mandatory: true,
+					ValueType: parser.ValueTypeString,
+				},
+				"interval": {
+					Name:      "interval",
+					ValueType: parser.ValueTypeTime,
+				},
+				"start-time": {
+					Name:      "start-time",
+					ValueType: parser.ValueTypeTime,
+				},
+				"on-event": {
+					Name:      "on-event",
+					Mandatory: true,
+					ValueType: parser.ValueTypeString,
+				},
+				"disabled": {
+					Name:      "disabled",
+					ValueType: parser.ValueTypeBool,
+				},
+			},
+		},
+		{
+			Type:           parser.CommandTypeSystem,
+			Path:           schedulerPath,
+			Name:           "print",
+			Description:    "prints the scheduled tasks",
+			SystemExecFunc: execSchedulerPrint,
+			Options:        parser.ListOptions(),
+		},
+		taskCommand("disable", "stops the runs of the task", func(ctx parser.SystemContext, name string) error {
+			return ctx.Scheduler().SetDisabled(name, true)
+		}),
+		taskCommand("enable", "resumes the runs of the task", func(ctx parser.SystemContext, name string) error {
+			return ctx.Scheduler().SetDisabled(name, false)
+		}),
+		taskCommand("remove", "drops the task", func(ctx parser.SystemContext, name string) error {
+			return ctx.Scheduler().Remove(name)
+		}),
+	}
+}
+
+// taskCommand builds a command applied to the task given by its name or its
+// number in print.
+func taskCommand(name, description string, apply func(ctx parser.SystemContext, name string) error) *parser.Command {
+	return &parser.Command{
+		Type:        parser.CommandTypeSystem,
+		Path:        schedulerPath,
+		Name:        name,
+		Description: description,
+		SystemExecFunc: func(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+			var names []string
+			for _, task := range ctx.Scheduler().List() {
+				names = append(names, task.Name)
+			}
+
+			name, err := itemName(ctx, flags.Get("numbers"), names, parser.ErrNoTask)
+			if err != nil {
+				return nil, err
+			}
+
+			if err = apply(ctx, name); err != nil {
+				return nil, fmt.Errorf("%w: %s", err, name)
+			}
+
+			return parser.NullValue, nil
+		},
+		Flags: map[string]*parser.Flag{
+			"numbers": {
+				Name:      "numbers",
+				Mandatory: true,
+				Number:    1,
+				ValueType: parser.ValueTypeString,
+			},
+		},
+	}
+}
+
+// execSchedulerAdd takes start-time as the time of the day, a task without an
+// interval runs the next time the day gets there.
+func execSchedulerAdd(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	name, err := flags.Get("name").Value(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	onEvent := flags.Get("on-event")
+	if onEvent.Expression().Type() != parser.ExpressionTypeCmdList {
+		return nil, fmt.Errorf("%w: on-event isn't a code block", parser.ErrWrongType)
+	}
+
+	task := parser.Task{
+		Name:    name.String(),
+		OnEvent: parser.Source(onEvent.Expression()),
+	}
+
+	if task.Interval, err = duration(ctx, flags.Get("interval")); err != nil {
+		return nil, err
+	}
+
+	startTime, err := duration(ctx, flags.Get("start-time"))
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case flags.Get("start-time") != nil:
+		now := ctx.Scheduler().Now()
+		task.Start = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).Add(startTime)
+
+		if task.Interval == 0 && task.Start.Before(now) {
+			task.Start = task.Start.Add(24 * time.Hour)
+		}
+	case task.Interval == 0:
+		return nil, errNoSchedule
+	}
+
+	if f := flags.Get("disabled"); f != nil {
+		disabled, err := f.Value(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		task.Disabled = parser.IsYes(disabled)
+	}
+
+	err = ctx.Scheduler().Add(ctx, task, func(ctx parser.SystemContext) error {
+		_, err := onEvent.Value(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, task.Name)
+	}
+
+	return parser.NullValue, nil
+}
+
+// execSchedulerPrint marks the disabled tasks with X.
+func execSchedulerPrint(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	mode, err := options.ListMode()
+	if err != nil {
+		return nil, err
+	}
+
+	var items []terminal.ListItem
+
+	for i, task := range ctx.Scheduler().List() {
+		var flag, interval, next string
+		if task.Disabled {
+			flag = "X"
+		}
+
+		if task.Interval > 0 {
+			interval = parser.NewTimeValue(task.Interval).String()
+		}
+
+		if !task.NextRun.IsZero() {
+			next = task.NextRun.Format(timeLayout)
+		}
+
+		items = append(items, terminal.ListItem{
+			Number: i,
+			Flags:  flag,
+			Values: []string{task.Name, interval, next, strconv.Itoa(task.RunCount), task.LastError, task.OnEvent},
+		})
+	}
+
+	columns := []string{"name", "interval", "next-run", "run-count", "last-error", "on-event"}
+	ctx.Buffer().Push(terminal.NewItemList(mode, []terminal.ListFlag{{Letter: "X", Name: "disabled"}}, columns, items))
+
+	return parser.NullValue, nil
+}
+
+// itemName returns the name of the item given by its name or its number in
+// print, names are the names of the items in the order they are printed.
+func itemName(ctx parser.SystemContext, flag *parser.Flag, names []string, notFound error) (string, error) {
+	value, err := flag.Value(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if !value.IsNumber() {
+		return value.String(), nil
+	}
+
+	if n := value.Number(); n >= 0 && n < len(names) {
+		return names[n], nil
+	}
+
+	return "", fmt.Errorf("%w: %d", notFound, value.Number())
+}
+
+// duration returns the duration of an optional flag, zero if it isn't set.
+func duration(ctx parser.SystemContext, flag *parser.Flag) (time.Duration, error) {
+	if flag == nil {
+		return 0, nil
+	}
+
+	value, err := flag.Value(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	d, ok := parser.Duration(value)
+	if !ok {
+		return 0, parser.ErrWrongType
+	}
+
+	return d, nil
+}
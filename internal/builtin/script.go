@@ -0,0 +1,270 @@
+package builtin
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/blkmlk/microshell/internal/parser"
+	"github.com/blkmlk/microshell/internal/terminal"
+)
+
+var (
+	scriptPath = []string{"system", "script"}
+	jobPath    = []string{"system", "script", "job"}
+)
+
+func scriptCommands() []*parser.Command {
+	return []*parser.Command{
+		{
+			Type:           parser.CommandTypeSystem,
+			Name:           "execute",
+			Description:    "runs the code block in the background and returns the id of its job",
+			SystemExecFunc: execExecute,
+			Flags: map[string]*parser.Flag{
+				"script": {
+					Name:      "script",
+					Mandatory: true,
+					Number:    1,
+					ValueType: parser.ValueTypeString,
+				},
+			},
+		},
+		{
+			Type:           parser.CommandTypeSystem,
+			Path:           scriptPath,
+			Name:           "add",
+			Description:    "stores the source as a script run by its name",
+			SystemExecFunc: execScriptAdd,
+			Flags: map[string]*parser.Flag{
+				"name": {
+					Name:      "name",
+					Mandatory: true,
+					ValueType: parser.ValueTypeString,
+				},
+				"source": {
+					Name:      "source",
+					Mandatory: true,
+					ValueType: parser.ValueTypeString,
+				},
+			},
+		},
+		{
+			Type:           parser.CommandTypeSystem,
+			Path:           scriptPath,
+			Name:           "run",
+			Description:    "runs the stored script and returns its value",
+			SystemExecFunc: execScriptRun,
+			Flags: map[string]*parser.Flag{
+				"numbers": {
+					Name:      "numbers",
+					Mandatory: true,
+					Number:    1,
+					ValueType: parser.ValueTypeString,
+				},
+			},
+		},
+		{
+			Type:           parser.CommandTypeSystem,
+			Path:           scriptPath,
+			Name:           "print",
+			Description:    "prints the stored scripts",
+			SystemExecFunc: execScriptPrint,
+			Options:        parser.ListOptions(),
+		},
+		{
+			Type:           parser.CommandTypeSystem,
+			Path:           scriptPath,
+			Name:           "remove",
+			Description:    "drops the stored script",
+			SystemExecFunc: execScriptRemove,
+			Flags: map[string]*parser.Flag{
+				"numbers": {
+					Name:      "numbers",
+					Mandatory: true,
+					Number:    1,
+					ValueType: parser.ValueTypeString,
+				},
+			},
+		},
+		{
+			Type:           parser.CommandTypeSystem,
+			Path:           jobPath,
+			Name:           "print",
+			Description:    "prints the running jobs",
+			SystemExecFunc: execJobPrint,
+			Options:        parser.ListOptions(),
+		},
+		{
+			Type:           parser.CommandTypeSystem,
+			Path:           jobPath,
+			Name:           "remove",
+			Description:    "stops the job",
+			SystemExecFunc: execJobRemove,
+			Flags: map[string]*parser.Flag{
+				"numbers": {
+					Name:      "numbers",
+					Mandatory: true,
+					Number:    1,
+					ValueType: parser.ValueTypeNumber,
+				},
+			},
+		},
+	}
+}
+
+// execExecute doesn't wait for the job, its output goes to the log.
+func execExecute(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	script := flags.Get("script")
+	if script.Expression().Type() != parser.ExpressionTypeCmdList {
+		return nil, fmt.Errorf("%w: script isn't a code block", parser.ErrWrongType)
+	}
+
+	job := ctx.Jobs().Start(ctx, parser.Source(script.Expression()), func(ctx parser.SystemContext) error {
+		_, err := script.Value(ctx)
+		return err
+	})
+
+	return parser.NewNumberValue(job.ID), nil
+}
+
+// execScriptAdd keeps the text of a code block without its braces, a string
+// is taken as the source itself.
+func execScriptAdd(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	name, err := flags.Get("name").Value(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var source string
+
+	flag := flags.Get("source")
+	if flag.Expression().Type() == parser.ExpressionTypeCmdList {
+		source = blockBody(parser.Source(flag.Expression()))
+	} else {
+		value, err := flag.Value(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		source = value.String()
+	}
+
+	err = ctx.Scripts().Add(ctx, name.String(), source)
+	if errors.Is(err, parser.ErrScriptExists) {
+		return nil, fmt.Errorf("%w: %s", err, name)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return parser.NullValue, nil
+}
+
+// blockBody returns the code of a code block, e.g. :put 1 of { :put 1 }.
+func blockBody(source string) string {
+	if !strings.HasPrefix(source, "{") || !strings.HasSuffix(source, "}") {
+		return source
+	}
+
+	return strings.TrimSpace(source[1 : len(source)-1])
+}
+
+func execScriptRun(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	name, err := scriptName(ctx, flags.Get("numbers"))
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := ctx.Scripts().Run(ctx, name)
+	if errors.Is(err, parser.ErrNoScript) {
+		return nil, fmt.Errorf("%w: %s", err, name)
+	}
+
+	return value, err
+}
+
+func execScriptPrint(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	mode, err := options.ListMode()
+	if err != nil {
+		return nil, err
+	}
+
+	var items []terminal.ListItem
+
+	for i, script := range ctx.Scripts().List() {
+		var last string
+		if !script.LastRun.IsZero() {
+			last = script.LastRun.Format(timeLayout)
+		}
+
+		items = append(items, terminal.ListItem{
+			Number: i,
+			Values: []string{script.Name, script.Owner, last, strconv.Itoa(script.RunCount), script.Source},
+		})
+	}
+
+	columns := []string{"name", "owner", "last-run", "run-count", "source"}
+	ctx.Buffer().Push(terminal.NewItemList(mode, nil, columns, items))
+
+	return parser.NullValue, nil
+}
+
+func execScriptRemove(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	name, err := scriptName(ctx, flags.Get("numbers"))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = ctx.Scripts().Remove(name); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, name)
+	}
+
+	return parser.NullValue, nil
+}
+
+func scriptName(ctx parser.SystemContext, flag *parser.Flag) (string, error) {
+	var names []string
+	for _, script := range ctx.Scripts().List() {
+		names = append(names, script.Name)
+	}
+
+	return itemName(ctx, flag, names, parser.ErrNoScript)
+}
+
+// execJobPrint numbers the jobs by their ids, remove takes them.
+func execJobPrint(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	mode, err := options.ListMode()
+	if err != nil {
+		return nil, err
+	}
+
+	var items []terminal.ListItem
+
+	for _, job := range ctx.Jobs().List() {
+		items = append(items, terminal.ListItem{
+			Number: job.ID,
+			Values: []string{job.Owner, job.Started.Format(timeLayout), job.Source},
+		})
+	}
+
+	columns := []string{"owner", "started", "source"}
+	ctx.Buffer().Push(terminal.NewItemList(mode, nil, columns, items))
+
+	return parser.NullValue, nil
+}
+
+func execJobRemove(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	id, err := number(ctx, flags.Get("numbers"))
+	if err != nil {
+		return nil, err
+	}
+
+	if !ctx.Jobs().Remove(id) {
+		return nil, fmt.Errorf("%w: %d", parser.ErrNoItem, id)
+	}
+
+	return parser.NullValue, nil
+}
@@ -0,0 +1,245 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/blkmlk/microshell/internal/parser"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrUnknownHandler   = errors.New("unknown handler")
+	ErrUnknownValueType = errors.New("unknown value type")
+	ErrDuplicate        = errors.New("duplicate definition")
+	ErrNoName           = errors.New("no name")
+	ErrNoHandler        = errors.New("no handler")
+)
+
+// Handlers binds the handler names used in a catalog to their functions.
+type Handlers map[string]parser.ExecFunc
+
+type document struct {
+	Menus    []*menu    `yaml:"menus"`
+	Commands []*command `yaml:"commands"`
+}
+
+type menu struct {
+	Path        string     `yaml:"path"`
+	Description string     `yaml:"description"`
+	Commands    []*command `yaml:"commands"`
+	Menus       []*menu    `yaml:"menus"`
+}
+
+type command struct {
+	Name        string    `yaml:"name"`
+	Description string    `yaml:"description"`
+	Handler     string    `yaml:"handler"`
+	Timeout     string    `yaml:"timeout"`
+	Flags       []*flag   `yaml:"flags"`
+	Options     []*option `yaml:"options"`
+}
+
+type flag struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Mandatory   bool   `yaml:"mandatory"`
+	Number      uint   `yaml:"number"`
+	Secret      bool   `yaml:"secret"`
+	Type        string `yaml:"type"`
+}
+
+type option struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+}
+
+// Catalog accumulates the commands of one or more catalog files.
+type Catalog struct {
+	handlers Handlers
+	commands []*parser.Command
+	menus    []*parser.Menu
+	defined  map[string]bool
+}
+
+func New(handlers Handlers) *Catalog {
+	return &Catalog{
+		handlers: handlers,
+		defined:  make(map[string]bool),
+	}
+}
+
+// LoadFile reads the catalog file at path.
+func (c *Catalog) LoadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	if err = c.Load(file); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	return nil
+}
+
+// Load reads a catalog document from r.
+func (c *Catalog) Load(r io.Reader) error {
+	var doc document
+
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(&doc); err != nil && err != io.EOF {
+		return err
+	}
+
+	for _, cmd := range doc.Commands {
+		if err := c.addCommand(nil, cmd); err != nil {
+			return err
+		}
+	}
+
+	for _, m := range doc.Menus {
+		if err := c.addMenu(nil, m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// List validates the loaded commands the same way the parser does and returns
+// them as a command list along with the descriptions of their menus.
+func (c *Catalog) List() (parser.List, error) {
+	list := parser.List{Commands: c.commands, Menus: c.menus}
+
+	if _, err := list.Items(); err != nil {
+		return parser.List{}, err
+	}
+
+	return list, nil
+}
+
+func (c *Catalog) addMenu(path []string, m *menu) error {
+	menuPath := append(append([]string{}, path...), splitPath(m.Path)...)
+
+	if len(menuPath) == len(path) {
+		return fmt.Errorf("menu under /%s: %w", strings.Join(path, " "), ErrNoName)
+	}
+
+	if m.Description != "" {
+		c.menus = append(c.menus, &parser.Menu{Path: menuPath, Description: m.Description})
+	}
+
+	for _, cmd := range m.Commands {
+		if err := c.addCommand(menuPath, cmd); err != nil {
+			return err
+		}
+	}
+
+	for _, sub := range m.Menus {
+		if err := c.addMenu(menuPath, sub); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Catalog) addCommand(path []string, cmd *command) error {
+	fullName := "/" + strings.Join(append(append([]string{}, path...), cmd.Name), " ")
+
+	if cmd.Name == "" {
+		return fmt.Errorf("command %s: %w", fullName, ErrNoName)
+	}
+
+	if c.defined[fullName] {
+		return fmt.Errorf("command %s: %w", fullName, ErrDuplicate)
+	}
+
+	result := &parser.Command{
+		Type:        parser.CommandTypeUser,
+		Path:        path,
+		Name:        cmd.Name,
+		Description: cmd.Description,
+		Flags:       make(parser.Flags),
+		Options:     make(parser.Options),
+	}
+
+	if cmd.Handler == "" {
+		return fmt.Errorf("command %s: %w", fullName, ErrNoHandler)
+	}
+
+	handler, ok := c.handlers[cmd.Handler]
+	if !ok {
+		return fmt.Errorf("command %s: %w %q", fullName, ErrUnknownHandler, cmd.Handler)
+	}
+
+	result.ExecFunc = handler
+
+	if cmd.Timeout != "" {
+		timeout, err := time.ParseDuration(cmd.Timeout)
+		if err != nil {
+			return fmt.Errorf("timeout of %s: %w", fullName, err)
+		}
+
+		result.Timeout = timeout
+	}
+
+	for _, f := range cmd.Flags {
+		if f.Name == "" {
+			return fmt.Errorf("flag of %s: %w", fullName, ErrNoName)
+		}
+
+		if result.Flags.Get(f.Name) != nil {
+			return fmt.Errorf("flag %s of %s: %w", f.Name, fullName, ErrDuplicate)
+		}
+
+		valueType := parser.ValueTypeString
+		if f.Type != "" {
+			var ok bool
+			if valueType, ok = parser.ValueTypeByName(f.Type); !ok {
+				return fmt.Errorf("flag %s of %s: %w %q", f.Name, fullName, ErrUnknownValueType, f.Type)
+			}
+		}
+
+		result.Flags.Set(&parser.Flag{
+			Name:        f.Name,
+			Description: f.Description,
+			Mandatory:   f.Mandatory,
+			Number:      f.Number,
+			Secret:      f.Secret,
+			ValueType:   valueType,
+		})
+	}
+
+	for _, o := range cmd.Options {
+		if o.Name == "" {
+			return fmt.Errorf("option of %s: %w", fullName, ErrNoName)
+		}
+
+		if _, ok := result.Options[o.Name]; ok || result.Flags.Get(o.Name) != nil {
+			return fmt.Errorf("option %s of %s: %w", o.Name, fullName, ErrDuplicate)
+		}
+
+		result.Options[o.Name] = false
+	}
+
+	c.defined[fullName] = true
+	c.commands = append(c.commands, result)
+
+	return nil
+}
+
+func splitPath(path string) []string {
+	return strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == ' '
+	})
+}
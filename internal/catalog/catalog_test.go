@@ -0,0 +1,168 @@
+package catalog
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blkmlk/microshell/internal/parser"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestCatalog(t *testing.T) {
+	suite.Run(t, new(catalogTestSuite))
+}
+
+type catalogTestSuite struct {
+	suite.Suite
+	handlers Handlers
+}
+
+func (t *catalogTestSuite) SetupTest() {
+	exec := func(ctx parser.Context, values parser.FlagValues, options parser.Options) (parser.Value, error) {
+		return parser.NullValue, nil
+	}
+
+	t.handlers = Handlers{
+		"firewall-add":   exec,
+		"firewall-print": exec,
+		"reboot":         exec,
+	}
+}
+
+func (t *catalogTestSuite) TestLoadFile() {
+	c := New(t.handlers)
+	t.Require().NoError(c.LoadFile("testdata/firewall.yaml"))
+
+	list, err := c.List()
+	t.Require().NoError(err)
+	t.Require().Len(list.Commands, 3)
+
+	commands := make(map[string]*parser.Command)
+	for _, cmd := range list.Commands {
+		commands["/"+strings.Join(append(append([]string{}, cmd.Path...), cmd.Name), " ")] = cmd
+	}
+
+	add := commands["/ip firewall add"]
+	t.Require().NotNil(add)
+	t.Require().NotNil(add.ExecFunc)
+	t.Require().Equal("Adds a firewall rule", add.Description)
+	t.Require().Len(add.Flags, 3)
+	t.Require().Equal(uint(1), add.Flags.Get("network").Number)
+	t.Require().Equal(parser.ValueTypeIPPrefix, add.Flags.Get("network").ValueType)
+	t.Require().True(add.Flags.Get("protocol").Mandatory)
+	t.Require().Equal(parser.ValueTypeNumber, add.Flags.Get("port").ValueType)
+	t.Require().Equal("Destination port", add.Flags.Get("port").Description)
+	t.Require().Contains(add.Options, "verbose")
+	t.Require().ElementsMatch([]string{"network", "protocol"}, add.MandatoryFlags)
+	t.Require().Equal(5*time.Second, add.Timeout)
+
+	t.Require().NotNil(commands["/ip firewall print"])
+	t.Require().NotNil(commands["/reboot"])
+
+	items, err := list.Items()
+	t.Require().NoError(err)
+
+	ip := items["ip"]
+	t.Require().Equal("IP configuration", ip.Payload.(*parser.Menu).Description)
+	t.Require().Equal([]string{"ip", "firewall"}, ip.Children["firewall"].Payload.(*parser.Menu).Path)
+	t.Require().Equal("Firewall rules", ip.Children["firewall"].Payload.(*parser.Menu).Description)
+}
+
+func (t *catalogTestSuite) TestErrors() {
+	t.Require().ErrorIs(t.load(`
+commands:
+  - name: add
+    handler: missing
+`), ErrUnknownHandler)
+
+	t.Require().ErrorIs(t.load(`
+menus:
+  - path: ip
+    commands:
+      - name: add
+`), ErrNoHandler)
+
+	t.Require().ErrorIs(t.load(`
+commands:
+  - name: add
+    handler: firewall-add
+    flags:
+      - name: port
+        type: float
+`), ErrUnknownValueType)
+
+	t.Require().ErrorIs(t.load(`
+commands:
+  - name: add
+    handler: firewall-add
+    flags:
+      - name: port
+      - name: port
+`), ErrDuplicate)
+
+	t.Require().ErrorIs(t.load(`
+menus:
+  - path: ip
+    commands:
+      - name: add
+        handler: firewall-add
+  - path: ip
+    commands:
+      - name: add
+        handler: firewall-add
+`), ErrDuplicate)
+
+	t.Require().ErrorIs(t.load(`
+commands:
+  - description: no name
+`), ErrNoName)
+
+	t.Require().Error(t.load(`
+commands:
+  - name: add
+    handler: firewall-add
+    mandatory: true
+`))
+
+	t.Require().Error(t.load(`
+commands:
+  - name: add
+    handler: firewall-add
+    timeout: soon
+`))
+}
+
+func (t *catalogTestSuite) TestValidation() {
+	c := New(t.handlers)
+	t.Require().NoError(c.Load(strings.NewReader(`
+commands:
+  - name: add
+    handler: firewall-add
+    flags:
+      - name: network
+        number: 1
+`)))
+	_, err := c.List()
+	t.Require().Error(err)
+
+	c = New(t.handlers)
+	t.Require().NoError(c.Load(strings.NewReader(`
+commands:
+  - name: add
+    handler: firewall-add
+    flags:
+      - name: network
+        mandatory: true
+        number: 1
+      - name: port
+        mandatory: true
+        number: 3
+`)))
+	_, err = c.List()
+	t.Require().Error(err)
+}
+
+func (t *catalogTestSuite) load(doc string) error {
+	return New(t.handlers).Load(strings.NewReader(doc))
+}
@@ -94,7 +94,7 @@ func (c *cursor) SetPosition(position int) {
 }
 
 func (c *cursor) GetRune() models.Rune {
-	return models.Rune(c.current.Text()[c.offset])
+	return models.Rune(c.current.RuneAt(c.offset))
 }
 
 func (c *cursor) Len() int {
@@ -263,12 +263,12 @@ func (c *cursor) Swap() int {
 
 	var runes = make([]rune, 2)
 
-	runes[1] = rune(c.current.Text()[c.offset])
+	runes[1] = c.current.RuneAt(c.offset)
 
 	c.Backspace()
 	c.MoveForward()
 
-	runes[0] = rune(c.current.Text()[c.offset])
+	runes[0] = c.current.RuneAt(c.offset)
 
 	c.Backspace()
 	n, _ := c.WriteString(string(runes))
@@ -295,7 +295,7 @@ func (c *cursor) DeleteToStart() int {
 			c.current.prev = c.root
 		}
 
-		c.current.SetText(c.current.Text()[c.offset+1:])
+		c.current.SetText(c.current.Slice(c.offset+1, c.current.Len()))
 	} else {
 		c.root.next = c.current.next
 
@@ -320,7 +320,7 @@ func (c *cursor) DeleteToEnd() int {
 	var n = c.current.End() - c.offset
 
 	if c.offset < c.current.End() {
-		c.current.SetText(c.current.Text()[:c.offset+1])
+		c.current.SetText(c.current.Slice(0, c.offset+1))
 	}
 
 	for c := c.current.next; c != nil; c = c.next {
@@ -353,7 +353,7 @@ func (c *cursor) DeleteToPrevWord() int {
 		n += c.current.prev.Len()
 
 		if c.offset < c.current.End() {
-			prev.SetText(prev.Text() + c.current.Text()[c.offset+1:])
+			prev.SetText(prev.Text() + c.current.Slice(c.offset+1, c.current.Len()))
 		}
 
 		c.offset = prev.End()
@@ -372,7 +372,7 @@ func (c *cursor) DeleteToPrevWord() int {
 	}
 
 	if c.offset < c.current.End() {
-		c.current.SetText(c.current.Text()[c.offset+1:])
+		c.current.SetText(c.current.Slice(c.offset+1, c.current.Len()))
 	} else {
 		c.current.prev.next = nil
 
@@ -415,7 +415,7 @@ func (c *cursor) AllWords() []string {
 	var words []string
 
 	for w := c.root.next; w != nil; w = w.next {
-		words = append(words, w.text)
+		words = append(words, w.Text())
 	}
 
 	return words
@@ -425,7 +425,7 @@ func (c *cursor) AllWordsFromCursor() []string {
 	var words []string
 
 	for w := c.current; w != nil; w = w.next {
-		words = append(words, w.text)
+		words = append(words, w.Text())
 	}
 
 	return words
@@ -436,7 +436,7 @@ func (c *cursor) Words() []string {
 
 	for w := c.root; w != nil; w = w.next {
 		if !w.IsSpace() {
-			words = append(words, w.text)
+			words = append(words, w.Text())
 		}
 	}
 
@@ -448,7 +448,7 @@ func (c *cursor) WordsFromCursor() []string {
 
 	for w := c.current; w != nil; w = w.next {
 		if !w.IsSpace() {
-			words = append(words, w.text)
+			words = append(words, w.Text())
 		}
 	}
 
@@ -469,7 +469,7 @@ func (c *cursor) WriteRune(r models.Rune) {
 		if c.offset == c.current.End() {
 			c.current.SetText(c.current.Text() + string(r))
 		} else {
-			c.current.SetText(c.current.Text()[:c.offset+1] + string(r) + c.current.Text()[c.offset+1:])
+			c.current.SetText(c.current.Slice(0, c.offset+1) + string(r) + c.current.Slice(c.offset+1, c.current.Len()))
 		}
 
 		c.offset++
@@ -498,7 +498,7 @@ func (c *cursor) WriteRune(r models.Rune) {
 
 	nextWord := newWord()
 
-	nextWord.SetText(c.current.Text()[c.offset+1:])
+	nextWord.SetText(c.current.Slice(c.offset+1, c.current.Len()))
 	nextWord.next = c.current.next
 	c.current.text = c.current.text[:c.offset+1]
 
@@ -518,11 +518,13 @@ func (c *cursor) WriteRune(r models.Rune) {
 }
 
 func (c *cursor) WriteString(str string) (int, error) {
+	var n int
 	for _, r := range str {
 		c.WriteRune(models.Rune(r))
+		n++
 	}
 
-	return len(str), nil
+	return n, nil
 }
 
 func (c *cursor) String() string {
@@ -572,7 +574,7 @@ func (c *cursor) StringFromPositionOffset(offset int) string {
 
 	builder := new(strings.Builder)
 
-	builder.WriteString(w.text[newOffset:])
+	builder.WriteString(w.Slice(newOffset, w.Len()))
 
 	for c := w.next; c != nil; c = c.next {
 		builder.WriteString(c.Text())
@@ -611,14 +613,14 @@ func (c *cursor) deleteChar() int {
 
 	// TODO: check offset decrease
 	if c.offset == c.current.End() {
-		c.current.SetText(c.current.Text()[:c.offset])
+		c.current.SetText(c.current.Slice(0, c.offset))
 		c.offset = c.current.End()
 	} else if c.offset == 0 {
-		c.current.SetText(c.current.Text()[c.offset+1:])
+		c.current.SetText(c.current.Slice(c.offset+1, c.current.Len()))
 		c.current = c.current.prev
 		c.offset = c.current.End()
 	} else {
-		c.current.SetText(c.current.Text()[:c.offset] + c.current.Text()[c.offset+1:])
+		c.current.SetText(c.current.Slice(0, c.offset) + c.current.Slice(c.offset+1, c.current.Len()))
 		c.offset--
 	}
 
@@ -3,6 +3,8 @@ package cursor
 import (
 	"testing"
 
+	"github.com/blkmlk/microshell/internal/models"
+
 	"github.com/stretchr/testify/require"
 )
 
@@ -94,6 +96,31 @@ func TestCursor_WriteString(t *testing.T) {
 	testCase(t, w, "abc", []actionTest{}, []string{"abc"}, 0)
 }
 
+func TestCursor_Unicode(t *testing.T) {
+	w := NewCursor()
+	w.WriteRune('а')
+	w.WriteRune('б')
+	w.WriteRune('г')
+
+	require.Equal(t, 1, w.MoveBackward())
+	require.Equal(t, models.Rune('б'), w.GetRune())
+	w.WriteRune('в')
+	require.Equal(t, []string{"абвг"}, w.AllWords())
+
+	require.Equal(t, 1, w.MoveBackward())
+	w.WriteRune(' ')
+	require.Equal(t, []string{"аб", " ", "вг"}, w.AllWords())
+	require.Equal(t, 6, w.Len())
+	require.Equal(t, " вг", w.StringFromPosition())
+
+	testCase(t, w, "世界 人", []actionTest{
+		{w.MoveToNextWord, 2, "move_next_word"},
+		{w.Backspace, 1, "backspace"},
+		{w.MoveToEnd, 2, "move_to_end"},
+		{w.Swap, 2, "swap"},
+	}, []string{"世人", " "}, 3)
+}
+
 func TestCursor_SetPosition(t *testing.T) {
 	w := NewCursor().(*cursor)
 
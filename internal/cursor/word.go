@@ -1,18 +1,29 @@
 package cursor
 
+// word keeps its text in runes, so the offsets of the cursor are counted in
+// characters rather than bytes.
 type word struct {
-	text string
+	text []rune
 
 	next *word
 	prev *word
 }
 
 func (w *word) Text() string {
-	return w.text
+	return string(w.text)
 }
 
 func (w *word) SetText(text string) {
-	w.text = text
+	w.text = []rune(text)
+}
+
+// Slice returns the text between the rune offsets from and to.
+func (w *word) Slice(from, to int) string {
+	return string(w.text[from:to])
+}
+
+func (w *word) RuneAt(offset int) rune {
+	return w.text[offset]
 }
 
 func (w *word) IsSpace() bool {
@@ -33,7 +44,7 @@ func (w *word) End() int {
 
 func newWord() *word {
 	return &word{
-		text: "",
+		text: nil,
 		next: nil,
 		prev: nil,
 	}
@@ -1,6 +1,8 @@
 package history
 
 import (
+	"time"
+
 	"github.com/blkmlk/microshell/internal/cursor"
 	"github.com/sarulabs/di/v2"
 )
@@ -16,11 +18,34 @@ var (
 	}
 )
 
+// Options configure the history file.
+type Options struct {
+	// File keeps the history between the sessions, the history is only kept
+	// in memory when it is empty.
+	File string
+	// Size is the maximum number of entries, zero means no limit.
+	Size int
+	// IgnoreSpace keeps the lines starting with a space out of the history.
+	IgnoreSpace bool
+	// PrefixSearch makes Up and Down walk only the entries starting with the
+	// text before the cursor.
+	PrefixSearch bool
+}
+
 type History interface {
+	Open(options Options) error
+	Options() Options
 	Load(values []string)
 	Next() bool
 	Prev() bool
+	NextPrefix(prefix string) bool
+	PrevPrefix(prefix string) bool
+	Search(query string, older bool) bool
+	Index() int
+	Seek(index int) bool
 	Cursor() cursor.Cursor
 	Value() string
+	Time() time.Time
 	Push() bool
+	Discard()
 }
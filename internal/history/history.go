@@ -1,7 +1,13 @@
 package history
 
 import (
+	"bufio"
 	"container/list"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/blkmlk/microshell/internal/cursor"
 )
@@ -9,6 +15,7 @@ import (
 func newHistory() History {
 	h := &history{
 		list: new(list.List),
+		now:  time.Now,
 	}
 	h.current = h.list.PushBack(&record{
 		id:     1,
@@ -22,12 +29,18 @@ func newHistory() History {
 type record struct {
 	id     int
 	value  string
+	line   string
+	time   time.Time
 	cursor cursor.Cursor
 }
 
 type history struct {
 	list    *list.List
 	current *list.Element
+	options Options
+	// stored is the number of entries in the file
+	stored int
+	now    func() time.Time
 }
 
 func (h *history) Next() bool {
@@ -48,10 +61,96 @@ func (h *history) Prev() bool {
 	return false
 }
 
+// PrevPrefix moves to the previous entry starting with prefix and differing
+// from the current line.
+func (h *history) PrevPrefix(prefix string) bool {
+	line := h.currentLine()
+
+	for e := h.current.Prev(); e != nil; e = e.Prev() {
+		if r := e.Value.(*record); strings.HasPrefix(r.line, prefix) && r.line != line {
+			h.current = e
+			return true
+		}
+	}
+
+	return false
+}
+
+// NextPrefix moves to the next entry starting with prefix, the new line is
+// always reached.
+func (h *history) NextPrefix(prefix string) bool {
+	line := h.currentLine()
+
+	for e := h.current.Next(); e != nil; e = e.Next() {
+		if r := e.Value.(*record); e == h.list.Back() || strings.HasPrefix(r.line, prefix) && r.line != line {
+			h.current = e
+			return true
+		}
+	}
+
+	return false
+}
+
+// Search moves to the newest entry containing query starting from the
+// current one, older skips the current entry. An edited entry is searched as
+// it is shown.
+func (h *history) Search(query string, older bool) bool {
+	e := h.current
+	if older || e == h.list.Back() {
+		e = e.Prev()
+	}
+
+	for ; e != nil; e = e.Prev() {
+		if strings.Contains(e.Value.(*record).text(), query) {
+			h.current = e
+			return true
+		}
+	}
+
+	return false
+}
+
+// Index returns the position of the current entry counted from the oldest
+// one.
+func (h *history) Index() int {
+	i := 0
+	for e := h.current.Prev(); e != nil; e = e.Prev() {
+		i++
+	}
+
+	return i
+}
+
+// Seek moves to the entry at index counted from the oldest one.
+func (h *history) Seek(index int) bool {
+	if index < 0 || index >= h.list.Len() {
+		return false
+	}
+
+	e := h.list.Front()
+	for i := 0; i < index; i++ {
+		e = e.Next()
+	}
+
+	h.current = e
+
+	return true
+}
+
 func (h *history) Value() string {
 	return h.currentRecord().value
 }
 
+// Time returns the time the current entry was executed at, zero for the
+// entries without a timestamp.
+func (h *history) Time() time.Time {
+	return h.currentRecord().time
+}
+
+func (h *history) Options() Options {
+	return h.options
+}
+
 func (h *history) Cursor() cursor.Cursor {
 	return h.currentRecord().cursor
 }
@@ -67,6 +166,7 @@ func (h *history) Load(values []string) {
 		r := &record{
 			id:     i,
 			value:  v,
+			line:   v,
 			cursor: c,
 		}
 
@@ -74,11 +174,56 @@ func (h *history) Load(values []string) {
 		i++
 	}
 
-	r := new(record)
-	r.id = i
-	h.current = h.list.PushBack(r)
+	h.current = h.list.PushBack(&record{
+		id:     i,
+		value:  " ",
+		cursor: cursor.NewCursor(),
+	})
 }
 
+// Open loads the entries of the history file and appends the new ones to it
+// on Push. A missing file is created on the first Push.
+func (h *history) Open(options Options) error {
+	h.options = options
+
+	if options.File == "" {
+		return nil
+	}
+
+	entries, err := readEntries(options.File)
+	if err != nil {
+		return err
+	}
+
+	h.list = new(list.List)
+
+	for i, e := range entries {
+		h.list.PushBack(&record{
+			id:     i + 1,
+			value:  " " + e.line,
+			line:   e.line,
+			time:   e.time,
+			cursor: cursor.NewCursorFromString(" " + e.line),
+		})
+	}
+
+	h.current = h.list.PushBack(&record{
+		id:     len(entries) + 1,
+		value:  " ",
+		cursor: cursor.NewCursor(),
+	})
+
+	h.stored = len(entries)
+
+	if h.trim() {
+		return h.rewrite()
+	}
+
+	return nil
+}
+
+// Push saves the edited line as a new entry. The line is skipped when it
+// repeats the last entry or starts with a space and IgnoreSpace is set.
 func (h *history) Push() bool {
 	value := h.Cursor().String()
 
@@ -86,13 +231,19 @@ func (h *history) Push() bool {
 		return false
 	}
 
-	h.currentRecord().cursor = cursor.NewCursorFromString(h.currentRecord().value)
-
-	c := cursor.NewCursorFromString(value)
+	h.restore()
 
 	back := h.list.Back().Value.(*record)
+	line := strings.TrimPrefix(value, " ")
+
+	if h.ignored(line) {
+		return false
+	}
+
 	back.value = value
-	back.cursor = c
+	back.line = line
+	back.time = h.now()
+	back.cursor = cursor.NewCursorFromString(value)
 
 	h.current = h.list.PushBack(&record{
 		id:     back.id + 1,
@@ -100,9 +251,177 @@ func (h *history) Push() bool {
 		cursor: cursor.NewCursor(),
 	})
 
+	h.trim()
+
+	// the history keeps working in memory when the file can't be written
+	if h.options.File != "" {
+		if h.options.Size > 0 && h.stored >= h.options.Size {
+			_ = h.rewrite()
+		} else if h.append(back) == nil {
+			h.stored++
+		}
+	}
+
 	return true
 }
 
+// Discard drops the edited line without saving it.
+func (h *history) Discard() {
+	h.restore()
+}
+
+// restore reverts the changes of the current entry and returns to the new
+// line.
+func (h *history) restore() {
+	h.currentRecord().cursor = cursor.NewCursorFromString(h.currentRecord().value)
+
+	h.current = h.list.Back()
+	h.currentRecord().cursor = cursor.NewCursor()
+}
+
+func (h *history) ignored(line string) bool {
+	if h.options.IgnoreSpace && strings.HasPrefix(line, " ") {
+		return true
+	}
+
+	if prev := h.list.Back().Prev(); prev != nil {
+		return prev.Value.(*record).line == line
+	}
+
+	return false
+}
+
+// trim removes the oldest entries over the size limit.
+func (h *history) trim() bool {
+	if h.options.Size <= 0 {
+		return false
+	}
+
+	trimmed := false
+	for h.list.Len()-1 > h.options.Size {
+		h.list.Remove(h.list.Front())
+		trimmed = true
+	}
+
+	return trimmed
+}
+
+func (h *history) append(r *record) error {
+	f, err := os.OpenFile(h.options.File, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err = f.WriteString(formatEntry(r)); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// rewrite replaces the file with the entries kept in memory.
+func (h *history) rewrite() error {
+	tmp, err := os.CreateTemp(filepath.Dir(h.options.File), filepath.Base(h.options.File)+".*")
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(tmp)
+	stored := 0
+
+	for e := h.list.Front(); e != h.list.Back(); e = e.Next() {
+		w.WriteString(formatEntry(e.Value.(*record)))
+		stored++
+	}
+
+	if err = w.Flush(); err == nil {
+		err = tmp.Close()
+	} else {
+		tmp.Close()
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), h.options.File)
+	}
+
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	h.stored = stored
+
+	return nil
+}
+
 func (h *history) currentRecord() *record {
 	return h.current.Value.(*record)
 }
+
+func (h *history) currentLine() string {
+	return h.currentRecord().text()
+}
+
+// text returns the line of the entry with the edits not entered yet.
+func (r *record) text() string {
+	return strings.TrimPrefix(r.cursor.String(), " ")
+}
+
+type entry struct {
+	line string
+	time time.Time
+}
+
+// readEntries reads a file of the bash format, every line may be preceded by
+// its unix time written as a comment.
+func readEntries(name string) ([]entry, error) {
+	f, err := os.Open(name)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	var (
+		entries []entry
+		t       time.Time
+	)
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if strings.HasPrefix(line, "#") {
+			if sec, err := strconv.ParseInt(line[1:], 10, 64); err == nil {
+				t = time.Unix(sec, 0)
+				continue
+			}
+		}
+
+		if line == "" {
+			continue
+		}
+
+		entries = append(entries, entry{line: line, time: t})
+		t = time.Time{}
+	}
+
+	return entries, scanner.Err()
+}
+
+func formatEntry(r *record) string {
+	var b strings.Builder
+
+	if !r.time.IsZero() {
+		b.WriteString("#" + strconv.FormatInt(r.time.Unix(), 10) + "\n")
+	}
+
+	b.WriteString(r.line + "\n")
+
+	return b.String()
+}
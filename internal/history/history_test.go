@@ -1,8 +1,11 @@
 package history
 
 import (
+	"io/ioutil"
+	"path/filepath"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/sarulabs/di/v2"
 	"github.com/stretchr/testify/suite"
@@ -72,3 +75,131 @@ func (t *historyTestSuite) TestHistoryPush() {
 	t.Require().Equal(" 1", t.history.Value())
 	t.Require().False(t.history.Prev())
 }
+
+func (t *historyTestSuite) push(line string) bool {
+	t.history.Cursor().Flush()
+	t.history.Cursor().WriteString(line)
+	return t.history.Push()
+}
+
+func (t *historyTestSuite) TestHistoryDuplicates() {
+	t.Require().True(t.push("1"))
+	t.Require().False(t.push("1"))
+	t.Require().True(t.push("2"))
+	t.Require().True(t.push("1"))
+	t.Require().Equal(4, t.history.list.Len())
+	t.Require().Equal(" ", t.history.Cursor().String())
+}
+
+func (t *historyTestSuite) TestHistoryIgnoreSpace() {
+	t.Require().NoError(t.history.Open(Options{IgnoreSpace: true}))
+
+	t.Require().False(t.push(" secret"))
+	t.Require().Equal(1, t.history.list.Len())
+	t.Require().Equal(" ", t.history.Cursor().String())
+
+	t.Require().True(t.push("public"))
+	t.Require().Equal(2, t.history.list.Len())
+}
+
+func (t *historyTestSuite) TestHistoryDiscard() {
+	t.Require().True(t.push("1"))
+
+	t.Require().True(t.history.Prev())
+	t.history.Cursor().WriteRune('2')
+	t.history.Discard()
+
+	t.Require().Equal(" ", t.history.Cursor().String())
+	t.Require().True(t.history.Prev())
+	t.Require().Equal(" 1", t.history.Cursor().String())
+}
+
+func (t *historyTestSuite) TestHistoryFile() {
+	file := filepath.Join(t.T().TempDir(), "history")
+	now := time.Unix(1600000000, 0)
+	t.history.now = func() time.Time { return now }
+
+	t.Require().NoError(t.history.Open(Options{File: file, Size: 3}))
+
+	for i := 1; i <= 3; i++ {
+		t.Require().True(t.push(":put " + strconv.Itoa(i)))
+	}
+
+	data, err := ioutil.ReadFile(file)
+	t.Require().NoError(err)
+	t.Require().Equal("#1600000000\n:put 1\n#1600000000\n:put 2\n#1600000000\n:put 3\n", string(data))
+
+	now = now.Add(time.Minute)
+	t.Require().True(t.push(":put 4"))
+	t.Require().Equal(4, t.history.list.Len())
+
+	opened := newHistory().(*history)
+	t.Require().NoError(opened.Open(Options{File: file, Size: 3}))
+	t.Require().Equal(4, opened.list.Len())
+
+	t.Require().True(opened.Prev())
+	t.Require().Equal(" :put 4", opened.Cursor().String())
+	t.Require().Equal(now, opened.Time())
+
+	t.Require().True(opened.Prev())
+	t.Require().True(opened.Prev())
+	t.Require().Equal(" :put 2", opened.Value())
+	t.Require().False(opened.Prev())
+}
+
+func (t *historyTestSuite) TestHistoryFileSize() {
+	file := filepath.Join(t.T().TempDir(), "history")
+	t.Require().NoError(ioutil.WriteFile(file, []byte("1\n2\n#1600000000\n3\n"), 0600))
+
+	t.Require().NoError(t.history.Open(Options{File: file, Size: 2}))
+	t.Require().Equal(3, t.history.list.Len())
+
+	t.Require().True(t.history.Prev())
+	t.Require().Equal(time.Unix(1600000000, 0), t.history.Time())
+	t.Require().True(t.history.Prev())
+	t.Require().True(t.history.Time().IsZero())
+
+	data, err := ioutil.ReadFile(file)
+	t.Require().NoError(err)
+	t.Require().Equal("2\n#1600000000\n3\n", string(data))
+}
+
+func (t *historyTestSuite) TestHistoryPrefix() {
+	for _, line := range []string{"/ip add 1", ":put 1", "/ip remove 1", "/ip add 2"} {
+		t.Require().True(t.push(line))
+	}
+
+	t.Require().True(t.history.PrevPrefix("/ip a"))
+	t.Require().Equal(" /ip add 2", t.history.Value())
+	t.Require().True(t.history.PrevPrefix("/ip a"))
+	t.Require().Equal(" /ip add 1", t.history.Value())
+	t.Require().False(t.history.PrevPrefix("/ip a"))
+
+	t.Require().True(t.history.NextPrefix("/ip a"))
+	t.Require().Equal(" /ip add 2", t.history.Value())
+	t.Require().True(t.history.NextPrefix("/ip a"))
+	t.Require().Equal(" ", t.history.Value())
+	t.Require().False(t.history.NextPrefix("/ip a"))
+}
+
+func (t *historyTestSuite) TestHistorySearch() {
+	for _, line := range []string{"/ip add 1", ":put 1", "/ip add 2"} {
+		t.Require().True(t.push(line))
+	}
+
+	index := t.history.Index()
+	t.Require().Equal(3, index)
+
+	t.Require().True(t.history.Search("add", false))
+	t.Require().Equal(" /ip add 2", t.history.Value())
+	t.Require().True(t.history.Search("add", false))
+	t.Require().Equal(" /ip add 2", t.history.Value())
+	t.Require().True(t.history.Search("add", true))
+	t.Require().Equal(" /ip add 1", t.history.Value())
+	t.Require().False(t.history.Search("add", true))
+	t.Require().Equal(0, t.history.Index())
+
+	t.Require().True(t.history.Seek(index))
+	t.Require().Equal(" ", t.history.Value())
+	t.Require().False(t.history.Seek(index + 1))
+}
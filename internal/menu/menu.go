@@ -0,0 +1,655 @@
+// Package menu builds the commands of a RouterOS-like configuration menu
+// (add, set, remove, enable, disable, comment, print and find) around a list
+// of items kept in a store.
+package menu
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/blkmlk/microshell/internal/parser"
+	"github.com/blkmlk/microshell/internal/terminal"
+)
+
+var ErrNoItem = parser.ErrNoItem
+
+// listFlags are the letters of the flags column of print.
+var listFlags = []terminal.ListFlag{
+	{Letter: "X", Name: "disabled"},
+	{Letter: "D", Name: "dynamic"},
+}
+
+// Field is a property of the items, it becomes a flag of add, set and find.
+// The names disabled, comment and numbers are taken by the menu.
+type Field struct {
+	Name        string
+	Description string
+	// Mandatory fields must be given to add.
+	Mandatory bool
+	ValueType parser.ValueType
+	// Default is the value of a field add isn't given, a field without a
+	// default stays unset.
+	Default parser.Value
+}
+
+// convert returns the value as the type of the field. Unlike the flags of
+// the user commands a number or a bool must be one, yes and no are bools as
+// well.
+func (f Field) convert(v parser.Value) (parser.Value, error) {
+	v, err := f.ValueType.Convert(v)
+	if err != nil {
+		return nil, fmt.Errorf("flag %s: %w", f.Name, err)
+	}
+
+	switch f.ValueType {
+	case parser.ValueTypeNumber:
+		if v.IsNumber() {
+			return v, nil
+		}
+
+		if n, err := strconv.Atoi(v.String()); err == nil {
+			return parser.NewNumberValue(n), nil
+		}
+	case parser.ValueTypeBool:
+		switch {
+		case v.IsBool():
+			return v, nil
+		case v.String() == "yes":
+			return parser.NewBoolValue(true), nil
+		case v.String() == "no" || v.String() == "false":
+			return parser.NewBoolValue(false), nil
+		}
+	default:
+		return v, nil
+	}
+
+	return nil, fmt.Errorf("flag %s: %w: %q is not %s", f.Name, parser.ErrWrongType, v.String(), f.ValueType)
+}
+
+// Menu is a list of items under Path. The items are referred to by their
+// number in the last print of the session or by their id.
+type Menu struct {
+	Path   []string
+	Fields []Field
+	Store  Store
+}
+
+// numbersKey keeps the ids of the last print of the menu in the session.
+type numbersKey struct {
+	menu *Menu
+}
+
+// New returns a menu keeping its items in the store, in memory if it is nil.
+func New(path []string, fields []Field, store Store) *Menu {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+
+	return &Menu{
+		Path:   path,
+		Fields: fields,
+		Store:  store,
+	}
+}
+
+// FormatID returns the id as it is written in the shell.
+func FormatID(id int) string {
+	return fmt.Sprintf("*%X", id)
+}
+
+// ParseID returns the id written as *1A.
+func ParseID(s string) (int, bool) {
+	if !strings.HasPrefix(s, "*") {
+		return 0, false
+	}
+
+	id, err := strconv.ParseUint(s[1:], 16, 31)
+	if err != nil {
+		return 0, false
+	}
+
+	return int(id), true
+}
+
+// Commands returns the commands of the menu.
+func (m *Menu) Commands() []*parser.Command {
+	return []*parser.Command{
+		m.command("add", "adds an item and returns its id", m.execAdd, m.fieldFlags(true)),
+		m.command("set", "changes the items", m.execSet, m.numbersFlags(m.fieldFlags(false))),
+		m.command("remove", "removes the items", m.execRemove, m.numbersFlags(nil)),
+		m.command("enable", "enables the items", m.disabler(false), m.numbersFlags(nil)),
+		m.command("disable", "disables the items", m.disabler(true), m.numbersFlags(nil)),
+		m.command("comment", "sets the comment of the items", m.execComment, m.numbersFlags(map[string]*parser.Flag{
+			"comment": {
+				Name:      "comment",
+				Mandatory: true,
+				ValueType: parser.ValueTypeString,
+			},
+		})),
+		m.printCommand(),
+		m.command("find", "returns the ids of the items with the values given", m.execFind, m.whereFlags(m.fieldFlags(false))),
+	}
+}
+
+func (m *Menu) command(name, description string, exec parser.SystemExecFunc, flags map[string]*parser.Flag) *parser.Command {
+	return &parser.Command{
+		Type:           parser.CommandTypeSystem,
+		Path:           m.Path,
+		Name:           name,
+		Description:    description,
+		SystemExecFunc: exec,
+		Flags:          flags,
+	}
+}
+
+func (m *Menu) printCommand() *parser.Command {
+	command := m.command("print", "prints the items", m.execPrint, m.whereFlags(map[string]*parser.Flag{
+		"file": {
+			Name:        "file",
+			Description: "the file the output is written to instead",
+			ValueType:   parser.ValueTypeString,
+		},
+	}))
+
+	command.Options = parser.ListOptions()
+	command.Options["as-value"] = false
+	command.Options["count-only"] = false
+
+	return command
+}
+
+// fieldFlags returns a flag for every field, disabled and comment.
+func (m *Menu) fieldFlags(add bool) map[string]*parser.Flag {
+	flags := map[string]*parser.Flag{
+		"disabled": {
+			Name:      "disabled",
+			ValueType: parser.ValueTypeBool,
+		},
+		"comment": {
+			Name:      "comment",
+			ValueType: parser.ValueTypeString,
+		},
+	}
+
+	for _, field := range m.Fields {
+		flags[field.Name] = &parser.Flag{
+			Name:        field.Name,
+			Description: field.Description,
+			Mandatory:   add && field.Mandatory,
+			ValueType:   field.ValueType,
+		}
+	}
+
+	return flags
+}
+
+func (m *Menu) numbersFlags(flags map[string]*parser.Flag) map[string]*parser.Flag {
+	if flags == nil {
+		flags = make(map[string]*parser.Flag)
+	}
+
+	flags["numbers"] = &parser.Flag{
+		Name:      "numbers",
+		Mandatory: true,
+		Number:    1,
+		ValueType: parser.ValueTypeString,
+	}
+
+	return flags
+}
+
+// whereFlags adds the where query over the fields, e.g. print where port>1000.
+func (m *Menu) whereFlags(flags map[string]*parser.Flag) map[string]*parser.Flag {
+	if flags == nil {
+		flags = make(map[string]*parser.Flag)
+	}
+
+	fields := []string{"disabled", "dynamic", "comment"}
+	for _, field := range m.Fields {
+		fields = append(fields, field.Name)
+	}
+
+	flags["where"] = &parser.Flag{
+		Name:        "where",
+		Description: "the condition the items match",
+		Query:       true,
+		Fields:      fields,
+	}
+
+	return flags
+}
+
+func (m *Menu) execAdd(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	item := Item{Values: make(map[string]parser.Value)}
+
+	for _, field := range m.Fields {
+		if field.Default != nil {
+			item.Values[field.Name] = field.Default
+		}
+	}
+
+	if err := m.apply(ctx, flags, &item); err != nil {
+		return nil, err
+	}
+
+	id, err := m.Store.Add(item)
+	if err != nil {
+		return nil, err
+	}
+
+	return parser.NewStringValue(FormatID(id)), nil
+}
+
+func (m *Menu) execSet(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	return m.update(ctx, flags, func(item *Item) error {
+		return m.apply(ctx, flags, item)
+	})
+}
+
+func (m *Menu) execRemove(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	items, err := m.selected(ctx, flags.Get("numbers"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		if err = m.Store.Remove(item.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return parser.NullValue, nil
+}
+
+func (m *Menu) disabler(disabled bool) parser.SystemExecFunc {
+	return func(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+		return m.update(ctx, flags, func(item *Item) error {
+			item.Disabled = disabled
+			return nil
+		})
+	}
+}
+
+func (m *Menu) execComment(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	comment, err := flags.Get("comment").Value(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.update(ctx, flags, func(item *Item) error {
+		item.Comment = comment.String()
+		return nil
+	})
+}
+
+// execPrint numbers the items, set and the others of the session take the
+// numbers until its next print. as-value returns the items to a script
+// rather than printing them and count-only the number of them.
+func (m *Menu) execPrint(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	mode, err := options.ListMode()
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := m.Store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	if items, err = m.filter(ctx, flags.Get("where"), items); err != nil {
+		return nil, err
+	}
+
+	numbers := make([]int, 0, len(items))
+	for _, item := range items {
+		numbers = append(numbers, item.ID)
+	}
+
+	ctx.Session().Set(numbersKey{menu: m}, numbers)
+
+	if options.Get("as-value") {
+		if options.Get("count-only") {
+			return parser.NewNumberValue(len(items)), nil
+		}
+
+		return m.array(items), nil
+	}
+
+	var out terminal.Output
+	if options.Get("count-only") {
+		out = terminal.NewPlainText(strconv.Itoa(len(items)))
+	} else {
+		out = m.list(mode, items)
+	}
+
+	if f := flags.Get("file"); f != nil {
+		name, err := f.Value(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return parser.NullValue, writeOutput(name.String(), out)
+	}
+
+	ctx.Buffer().Push(out)
+
+	return parser.NullValue, nil
+}
+
+// execFind returns the ids of the items having all the values given.
+func (m *Menu) execFind(ctx parser.SystemContext, flags parser.Flags, options parser.Options) (parser.Value, error) {
+	var want Item
+	if err := m.apply(ctx, flags, &want); err != nil {
+		return nil, err
+	}
+
+	items, err := m.Store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	if items, err = m.filter(ctx, flags.Get("where"), items); err != nil {
+		return nil, err
+	}
+
+	var ids []parser.Value
+
+	for _, item := range items {
+		if m.matches(flags, item, want) {
+			ids = append(ids, parser.NewStringValue(FormatID(item.ID)))
+		}
+	}
+
+	return parser.NewArrayValue(ids...), nil
+}
+
+func (m *Menu) matches(flags parser.Flags, item, want Item) bool {
+	if flags.Get("disabled") != nil && item.Disabled != want.Disabled {
+		return false
+	}
+
+	if flags.Get("comment") != nil && item.Comment != want.Comment {
+		return false
+	}
+
+	for name, value := range want.Values {
+		if v, ok := item.Values[name]; !ok || !v.Equal(value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// filter returns the items for which the where query is true, all of them
+// without a query. An item doesn't match if the query compares a field it
+// hasn't set, e.g. port>1000 with no port set, other wrong types fail.
+func (m *Menu) filter(ctx parser.SystemContext, where *parser.Flag, items []Item) ([]Item, error) {
+	if where == nil {
+		return items, nil
+	}
+
+	var filtered []Item
+
+	for _, item := range items {
+		var unset bool
+
+		value, err := where.Value(parser.WithProperties(ctx, properties{menu: m, item: item, unset: &unset}))
+		if errors.Is(err, parser.ErrWrongType) && unset {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if parser.IsYes(value) {
+			filtered = append(filtered, item)
+		}
+	}
+
+	return filtered, nil
+}
+
+// properties are the fields of an item as a query sees them, an unset field
+// is nil and marks the query as unset.
+type properties struct {
+	menu  *Menu
+	item  Item
+	unset *bool
+}
+
+func (p properties) Property(name string) (parser.Value, bool) {
+	switch name {
+	case "disabled":
+		return parser.NewBoolValue(p.item.Disabled), true
+	case "dynamic":
+		return parser.NewBoolValue(p.item.Dynamic), true
+	case "comment":
+		return parser.NewStringValue(p.item.Comment), true
+	}
+
+	for _, field := range p.menu.Fields {
+		if field.Name != name {
+			continue
+		}
+
+		if value, ok := p.item.Values[name]; ok {
+			return value, true
+		}
+
+		if p.unset != nil {
+			*p.unset = true
+		}
+
+		return parser.NilValue, true
+	}
+
+	return nil, false
+}
+
+// apply sets the values of the flags given to the item.
+func (m *Menu) apply(ctx parser.SystemContext, flags parser.Flags, item *Item) error {
+	if item.Values == nil {
+		item.Values = make(map[string]parser.Value)
+	}
+
+	if f := flags.Get("disabled"); f != nil {
+		value, err := f.Value(ctx)
+		if err != nil {
+			return err
+		}
+
+		if value, err = (Field{Name: "disabled", ValueType: parser.ValueTypeBool}).convert(value); err != nil {
+			return err
+		}
+
+		item.Disabled = value.Bool()
+	}
+
+	if f := flags.Get("comment"); f != nil {
+		value, err := f.Value(ctx)
+		if err != nil {
+			return err
+		}
+
+		item.Comment = value.String()
+	}
+
+	for _, field := range m.Fields {
+		f := flags.Get(field.Name)
+		if f == nil {
+			continue
+		}
+
+		value, err := f.Value(ctx)
+		if err != nil {
+			return err
+		}
+
+		if value, err = field.convert(value); err != nil {
+			return err
+		}
+
+		item.Values[field.Name] = value
+	}
+
+	return nil
+}
+
+// update changes the items given by numbers and stores them.
+func (m *Menu) update(ctx parser.SystemContext, flags parser.Flags, change func(item *Item) error) (parser.Value, error) {
+	items, err := m.selected(ctx, flags.Get("numbers"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		if err = change(&item); err != nil {
+			return nil, err
+		}
+
+		if err = m.Store.Set(item); err != nil {
+			return nil, err
+		}
+	}
+
+	return parser.NullValue, nil
+}
+
+// selected returns the items given by their numbers or ids, an array takes
+// several of them.
+func (m *Menu) selected(ctx parser.SystemContext, flag *parser.Flag) ([]Item, error) {
+	value, err := flag.Value(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := m.Store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]Item, len(items))
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+
+	numbers, _ := ctx.Session().Get(numbersKey{menu: m}).([]int)
+
+	// the numbers follow the store until the first print
+	if numbers == nil {
+		for _, item := range items {
+			numbers = append(numbers, item.ID)
+		}
+	}
+
+	var selected []Item
+
+	for _, v := range values(value) {
+		id, ok := ParseID(v.String())
+
+		if !ok && v.IsNumber() && v.Number() >= 0 && v.Number() < len(numbers) {
+			id, ok = numbers[v.Number()], true
+		}
+
+		item, exists := byID[id]
+		if !ok || !exists {
+			return nil, fmt.Errorf("%w: %s", ErrNoItem, v)
+		}
+
+		selected = append(selected, item)
+	}
+
+	return selected, nil
+}
+
+func values(value parser.Value) []parser.Value {
+	array, ok := value.(parser.Array)
+	if !ok {
+		return []parser.Value{value}
+	}
+
+	var items []parser.Value
+	for _, item := range array.Items() {
+		items = append(items, values(item.Value)...)
+	}
+
+	return items
+}
+
+// list returns the items to print, the unset fields are left empty.
+func (m *Menu) list(mode terminal.ListMode, items []Item) terminal.Output {
+	columns := make([]string, len(m.Fields))
+	for i, field := range m.Fields {
+		columns[i] = field.Name
+	}
+
+	listItems := make([]terminal.ListItem, len(items))
+	for i, item := range items {
+		listItems[i] = terminal.ListItem{
+			Number:  i,
+			Comment: item.Comment,
+			Values:  make([]string, len(m.Fields)),
+		}
+
+		if item.Disabled {
+			listItems[i].Flags += "X"
+		}
+
+		if item.Dynamic {
+			listItems[i].Flags += "D"
+		}
+
+		for j, field := range m.Fields {
+			if value, ok := item.Values[field.Name]; ok {
+				listItems[i].Values[j] = value.String()
+			}
+		}
+	}
+
+	return terminal.NewItemList(mode, listFlags, columns, listItems)
+}
+
+// array returns the items as arrays of their properties and .id.
+func (m *Menu) array(items []Item) parser.Value {
+	values := make([]parser.Value, 0, len(items))
+
+	for _, item := range items {
+		properties := map[string]parser.Value{
+			".id":      parser.NewStringValue(FormatID(item.ID)),
+			"disabled": parser.NewBoolValue(item.Disabled),
+			"dynamic":  parser.NewBoolValue(item.Dynamic),
+		}
+
+		if item.Comment != "" {
+			properties["comment"] = parser.NewStringValue(item.Comment)
+		}
+
+		for name, value := range item.Values {
+			properties[name] = value
+		}
+
+		values = append(values, parser.NewKeyedArrayValue(properties))
+	}
+
+	return parser.NewArrayValue(values...)
+}
+
+// writeOutput writes the output to the file as it would be printed on a
+// terminal of unknown width.
+func writeOutput(name string, out terminal.Output) error {
+	var text strings.Builder
+	for _, w := range out.Words(0, 0) {
+		text.WriteString(w.Text())
+	}
+
+	if !strings.HasSuffix(text.String(), "\n") {
+		text.WriteString("\n")
+	}
+
+	return os.WriteFile(name, []byte(text.String()), 0644)
+}
@@ -0,0 +1,266 @@
+package menu
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blkmlk/microshell/internal/builtin"
+	"github.com/blkmlk/microshell/internal/logger"
+	"github.com/blkmlk/microshell/internal/parser"
+	"github.com/blkmlk/microshell/internal/script"
+	"github.com/blkmlk/microshell/internal/terminal"
+	"github.com/sarulabs/di/v2"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestMenu(t *testing.T) {
+	suite.Run(t, new(menuTestSuite))
+}
+
+type menuTestSuite struct {
+	suite.Suite
+	menu    *Menu
+	builder *di.Builder
+	runner  *script.Runner
+	out     *bytes.Buffer
+}
+
+func (t *menuTestSuite) SetupTest() {
+	t.menu = New([]string{"ip", "service"}, []Field{
+		{Name: "name", Mandatory: true, ValueType: parser.ValueTypeString},
+		{Name: "port", ValueType: parser.ValueTypeNumber, Default: parser.NewNumberValue(0)},
+		{Name: "address", ValueType: parser.ValueTypeIPPrefix},
+	}, nil)
+
+	builder, err := di.NewBuilder()
+	t.Require().NoError(err)
+
+	err = builder.Add(
+		parser.Definition,
+		parser.DefinitionContext,
+		parser.DefinitionClock,
+		parser.DefinitionScope,
+		logger.Definition,
+		terminal.DefinitionBuffer,
+		di.Def{
+			Name: parser.DefinitionNameCommandTree,
+			Build: func(ctn di.Container) (interface{}, error) {
+				return parser.List{Commands: append(builtin.Commands(), t.menu.Commands()...)}, nil
+			},
+		},
+	)
+	t.Require().NoError(err)
+
+	t.builder = builder
+	t.out = new(bytes.Buffer)
+	t.runner = script.NewRunner(builder.Build(), t.out)
+}
+
+func (t *menuTestSuite) TestAddPrint() {
+	t.Require().NoError(t.runner.RunString(`:put [/ip service add name=ssh port=22]`))
+	t.Require().NoError(t.runner.RunString(`/ip service add name=www port=80 address=10.0.0.0/8 comment="web" disabled=yes`))
+	t.Require().NoError(t.runner.RunString(`/ip service add name=api`))
+	t.Require().NoError(t.runner.RunString(`/ip service print`))
+
+	t.Require().Equal(`*1
+Flags: X - disabled, D - dynamic
+  #    NAME  PORT  ADDRESS
+  0    ssh   22
+  1 X  ;;; web
+       www   80    10.0.0.0/8
+  2    api   0
+`, t.out.String())
+
+	err := t.runner.RunString(`/ip service add port=1`)
+	t.Require().True(errors.Is(err, script.ErrRuntime))
+	t.Require().Contains(err.Error(), "no mandatory flag: name")
+}
+
+func (t *menuTestSuite) TestSet() {
+	t.Require().NoError(t.runner.RunString(`/ip service add name=ssh port=22; /ip service add name=www port=80; /ip service add name=api`))
+	t.Require().NoError(t.runner.RunString(`/ip service print`))
+
+	// the numbers of the print stay after a remove
+	t.Require().NoError(t.runner.RunString(`/ip service remove 0; /ip service set 1 port=8080`))
+	t.Require().NoError(t.runner.RunString(`/ip service disable *3; /ip service comment *2 comment="web"`))
+
+	items, err := t.menu.Store.List()
+	t.Require().NoError(err)
+	t.Require().Len(items, 2)
+	t.Require().Equal(2, items[0].ID)
+	t.Require().Equal("8080", items[0].Values["port"].String())
+	t.Require().Equal("web", items[0].Comment)
+	t.Require().True(items[1].Disabled)
+
+	t.Require().NoError(t.runner.RunString(`/ip service enable [/ip service find disabled=yes]`))
+
+	items, err = t.menu.Store.List()
+	t.Require().NoError(err)
+	t.Require().False(items[1].Disabled)
+
+	err = t.runner.RunString(`/ip service set 0 port=1`)
+	t.Require().True(errors.Is(err, script.ErrRuntime))
+	t.Require().Contains(err.Error(), "no such item: 0")
+
+	err = t.runner.RunString(`/ip service remove *9`)
+	t.Require().Contains(err.Error(), "no such item: *9")
+}
+
+func (t *menuTestSuite) TestTypes() {
+	for _, command := range []string{
+		`/ip service add name=ssh port=abc`,
+		`/ip service add name=ssh address=10.0.0.1`,
+		`/ip service add name=ssh disabled=maybe`,
+	} {
+		err := t.runner.RunString(command)
+		t.Require().True(errors.Is(err, script.ErrRuntime), command)
+		t.Require().Contains(err.Error(), "wrong type", command)
+	}
+
+	t.Require().NoError(t.runner.RunString(`/ip service add name=ssh port="22" address="10.0.0.0/8" disabled=yes`))
+
+	items, err := t.menu.Store.List()
+	t.Require().NoError(err)
+	t.Require().Len(items, 1)
+	t.Require().Equal(parser.KindNumber, items[0].Values["port"].Kind())
+	t.Require().Equal(parser.KindIPPrefix, items[0].Values["address"].Kind())
+	t.Require().True(items[0].Disabled)
+
+	t.Require().NoError(t.runner.RunString(`:put [/ip service find where address in 10.0.0.0/8]; :put [/ip service find port=22]`))
+	t.Require().Equal("*1\n*1\n", t.out.String())
+
+	err = t.runner.RunString(`/ip service set 0 port=ssh`)
+	t.Require().Contains(err.Error(), `flag port: wrong type: "ssh" is not number`)
+}
+
+func (t *menuTestSuite) TestFind() {
+	script := `/ip service add name=ssh port=22; /ip service add name=www port=80; /ip service add name=alt port=80
+:foreach id in=[/ip service find port=80] do={ :put $id }
+:put [:len [/ip service find]]`
+	t.Require().NoError(t.runner.RunString(script))
+	t.Require().Equal("*2\n*3\n3\n", t.out.String())
+}
+
+func (t *menuTestSuite) TestWhere() {
+	t.Require().NoError(t.runner.RunString(`/ip service add name=ssh port=22; /ip service add name=www port=8080 disabled=yes; /ip service add name=api port=8729 address=10.0.0.0/8`))
+
+	t.Require().NoError(t.runner.RunString(`/ip service print where port>1000 and not disabled`))
+	t.Require().Equal(`Flags: X - disabled, D - dynamic
+  #    NAME  PORT  ADDRESS
+  0    api   8729  10.0.0.0/8
+`, t.out.String())
+
+	// the numbers are those of the filtered print
+	t.Require().NoError(t.runner.RunString(`/ip service set 0 port=8730`))
+	items, err := t.menu.Store.List()
+	t.Require().NoError(err)
+	t.Require().Equal("8730", items[2].Values["port"].String())
+
+	// another session keeps its own numbers
+	other := script.NewRunner(t.builder.Build(), new(bytes.Buffer))
+	t.Require().NoError(other.RunString(`/ip service set 0 port=23`))
+	items, err = t.menu.Store.List()
+	t.Require().NoError(err)
+	t.Require().Equal("23", items[0].Values["port"].String())
+	t.Require().Equal("8730", items[2].Values["port"].String())
+
+	t.Require().NoError(t.runner.RunString(`/ip service set 0 port=8731`))
+	items, err = t.menu.Store.List()
+	t.Require().NoError(err)
+	t.Require().Equal("8731", items[2].Values["port"].String())
+
+	t.out.Reset()
+	script := `:foreach id in=[/ip service find where disabled=no] do={ :put $id }
+:put [/ip service find where name~"^w" or (port<100 and comment="")]; :put [/ip service find where address in 10.0.0.0/8]
+:put [:len [/ip service find where comment="web"]]`
+	t.Require().NoError(t.runner.RunString(script))
+	t.Require().Equal("*1\n*3\n*1;*2\n*3\n0\n", t.out.String())
+
+	err = t.runner.RunString(`/ip service print where port>`)
+	t.Require().Error(err)
+
+	// the commands in brackets are looked up from the menu of the command
+	t.out.Reset()
+	t.Require().NoError(t.runner.RunString(`/ip service set [find name=api] comment=hi; :put [/ip service find comment=hi]`))
+	t.Require().NoError(t.runner.RunString(`/ip service; :foreach i in=[find where disabled=no] do={ :put $i }`))
+	t.Require().Equal("*3\n*1\n*3\n", t.out.String())
+
+	err = t.runner.RunString(`/ip service print where bogus=1`)
+	t.Require().Error(err)
+	t.Require().Contains(err.Error(), "unknown field `bogus`")
+
+	// only the items without the field are skipped
+	err = t.runner.RunString(`/ip service print where port~"^8"`)
+	t.Require().Error(err)
+	t.Require().Contains(err.Error(), "wrong type")
+}
+
+func (t *menuTestSuite) TestPrintModes() {
+	t.Require().NoError(t.runner.RunString(`/ip service add name=ssh port=22 comment="remote shell"; /ip service add name=www port=80 address=10.0.0.0/8 disabled=yes`))
+	_, err := t.menu.Store.Add(Item{Dynamic: true, Values: map[string]parser.Value{"name": parser.NewStringValue("api")}})
+	t.Require().NoError(err)
+
+	t.Require().NoError(t.runner.RunString(`/ip service print detail`))
+	t.Require().Equal(`Flags: X - disabled, D - dynamic
+  0    ;;; remote shell
+       name=ssh port=22
+
+  1 X  name=www port=80 address=10.0.0.0/8
+
+  2 D  name=api
+`, t.out.String())
+
+	t.out.Reset()
+	t.Require().NoError(t.runner.RunString(`/ip service print terse where port>0`))
+	t.Require().Equal(`  0    comment="remote shell" name=ssh port=22
+  1 X  name=www port=80 address=10.0.0.0/8
+`, t.out.String())
+
+	t.out.Reset()
+	source := `:put [/ip service print count-only where disabled=no]
+:global items [/ip service print as-value]
+:put ($items->1->".id"); :put ($items->1->"address"); :put ($items->2->"dynamic")
+:put [/ip service print count-only as-value]`
+	t.Require().NoError(t.runner.RunString(source))
+	t.Require().Equal("2\n*2\n10.0.0.0/8\ntrue\n3\n", t.out.String())
+
+	t.out.Reset()
+	file := filepath.Join(t.T().TempDir(), "services.txt")
+	t.Require().NoError(t.runner.RunString(`/ip service print terse file="` + file + `"`))
+	t.Require().Empty(t.out.String())
+
+	data, err := os.ReadFile(file)
+	t.Require().NoError(err)
+	t.Require().Equal(`  0    comment="remote shell" name=ssh port=22
+  1 X  name=www port=80 address=10.0.0.0/8
+  2 D  name=api
+`, string(data))
+
+	// a path needs no quotes
+	bare := filepath.Join(t.T().TempDir(), "count_only.txt")
+	t.Require().NoError(t.runner.RunString(`/ip service print count-only file=` + bare))
+	t.Require().Empty(t.out.String())
+
+	data, err = os.ReadFile(bare)
+	t.Require().NoError(err)
+	t.Require().Equal("3\n", string(data))
+
+	err = t.runner.RunString(`/ip service print detail terse`)
+	t.Require().True(errors.Is(err, script.ErrRuntime))
+	t.Require().Contains(err.Error(), "detail and terse can't be used together")
+}
+
+func (t *menuTestSuite) TestParseID() {
+	id, ok := ParseID("*1A")
+	t.Require().True(ok)
+	t.Require().Equal(26, id)
+	t.Require().Equal("*1A", FormatID(id))
+
+	_, ok = ParseID("1A")
+	t.Require().False(ok)
+	_, ok = ParseID("*")
+	t.Require().False(ok)
+}
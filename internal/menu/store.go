@@ -0,0 +1,104 @@
+package menu
+
+import (
+	"sync"
+
+	"github.com/blkmlk/microshell/internal/parser"
+)
+
+// Item is an entry of a menu. ID is internal, it is written as *1A.
+type Item struct {
+	ID       int
+	Disabled bool
+	// Dynamic items are made by the program rather than by add.
+	Dynamic bool
+	Comment string
+	Values  map[string]parser.Value
+}
+
+// Copy returns the item with its own values.
+func (i Item) Copy() Item {
+	values := make(map[string]parser.Value, len(i.Values))
+	for name, value := range i.Values {
+		values[name] = value
+	}
+
+	i.Values = values
+
+	return i
+}
+
+// Store keeps the items of a menu.
+type Store interface {
+	// List returns the items in the order they are printed.
+	List() ([]Item, error)
+	// Add stores a new item and returns the id given to it.
+	Add(item Item) (int, error)
+	// Set replaces the item with the same id.
+	Set(item Item) error
+	Remove(id int) error
+}
+
+type memoryStore struct {
+	mu     sync.Mutex
+	lastID int
+	items  []Item
+}
+
+// NewMemoryStore returns a store which keeps the items until the program
+// exits.
+func NewMemoryStore() Store {
+	return new(memoryStore)
+}
+
+func (s *memoryStore) List() ([]Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items := make([]Item, 0, len(s.items))
+	for _, item := range s.items {
+		items = append(items, item.Copy())
+	}
+
+	return items, nil
+}
+
+func (s *memoryStore) Add(item Item) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastID++
+	item = item.Copy()
+	item.ID = s.lastID
+	s.items = append(s.items, item)
+
+	return item.ID, nil
+}
+
+func (s *memoryStore) Set(item Item) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.items {
+		if s.items[i].ID == item.ID {
+			s.items[i] = item.Copy()
+			return nil
+		}
+	}
+
+	return ErrNoItem
+}
+
+func (s *memoryStore) Remove(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.items {
+		if s.items[i].ID == id {
+			s.items = append(s.items[:i], s.items[i+1:]...)
+			return nil
+		}
+	}
+
+	return ErrNoItem
+}
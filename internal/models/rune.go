@@ -1,5 +1,7 @@
 package models
 
+import "unicode"
+
 type Rune rune
 
 func (r Rune) Is(rn rune) bool {
@@ -26,6 +28,11 @@ func (r Rune) IsAlpha() bool {
 	return r.IsLowerAlpha() || r.IsUpperAlpha()
 }
 
+// IsUnicodeLetter reports whether r is a letter outside of the ASCII range.
+func (r Rune) IsUnicodeLetter() bool {
+	return rune(r) > unicode.MaxASCII && unicode.IsLetter(rune(r))
+}
+
 func (r Rune) String() string {
 	return string(r)
 }
@@ -0,0 +1,21 @@
+package parser
+
+import "time"
+
+// Clock tells the time to the jobs and the scheduler, tests replace it with
+// a fake one.
+type Clock interface {
+	Now() time.Time
+	// After sends the time once d has passed.
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
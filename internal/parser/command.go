@@ -1,13 +1,77 @@
 package parser
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/blkmlk/microshell/internal/terminal"
+)
+
 type ValueType int
 
 const (
 	ValueTypeString ValueType = iota
 	ValueTypeNumber
 	ValueTypeBool
+	ValueTypeIP
+	ValueTypeIPPrefix
+	ValueTypeIP6
+	ValueTypeIP6Prefix
+	ValueTypeTime
 )
 
+var valueTypeNames = map[ValueType]string{
+	ValueTypeString:    "string",
+	ValueTypeNumber:    "number",
+	ValueTypeBool:      "bool",
+	ValueTypeIP:        "ip",
+	ValueTypeIPPrefix:  "ip-prefix",
+	ValueTypeIP6:       "ip6",
+	ValueTypeIP6Prefix: "ip6-prefix",
+	ValueTypeTime:      "time",
+}
+
+func (t ValueType) String() string {
+	return valueTypeNames[t]
+}
+
+func ValueTypeByName(name string) (ValueType, bool) {
+	for t, n := range valueTypeNames {
+		if n == name {
+			return t, true
+		}
+	}
+
+	return ValueTypeString, false
+}
+
+// Convert returns the value as the type, the address, network and time types
+// fail on a value of another kind. A number is taken as seconds for the time.
+func (t ValueType) Convert(v Value) (Value, error) {
+	switch t {
+	case ValueTypeIP, ValueTypeIP6:
+		if ip, ok := asTyped(v).(*ipValue); ok && ip.Is6() == (t == ValueTypeIP6) {
+			return ip, nil
+		}
+	case ValueTypeIPPrefix, ValueTypeIP6Prefix:
+		if prefix, ok := asTyped(v).(*prefixValue); ok && prefix.Is6() == (t == ValueTypeIP6Prefix) {
+			return prefix, nil
+		}
+	case ValueTypeTime:
+		if d, ok := Duration(v); ok {
+			return NewTimeValue(d), nil
+		}
+	default:
+		return v, nil
+	}
+
+	return nil, fmt.Errorf("%w: %q is not %s", ErrWrongType, v.String(), t)
+}
+
 type FlagValues map[string]Value
 
 func (fv FlagValues) Get(name string) (Value, bool) {
@@ -46,6 +110,32 @@ func (o Options) Set(name string) {
 	o[name] = true
 }
 
+// ErrListMode is the failure of a print given both detail and terse.
+var ErrListMode = errors.New("detail and terse can't be used together")
+
+// ListOptions are the options of a print choosing the form of the list.
+func ListOptions() Options {
+	return Options{
+		"detail": false,
+		"terse":  false,
+	}
+}
+
+// ListMode returns the form of the list the detail and terse options ask
+// for, a table without them.
+func (o Options) ListMode() (terminal.ListMode, error) {
+	switch {
+	case o.Get("detail") && o.Get("terse"):
+		return terminal.ListTable, ErrListMode
+	case o.Get("detail"):
+		return terminal.ListDetail, nil
+	case o.Get("terse"):
+		return terminal.ListTerse, nil
+	}
+
+	return terminal.ListTable, nil
+}
+
 type CommandType string
 
 const (
@@ -65,20 +155,53 @@ type Command struct {
 	Type           CommandType
 	Path           []string
 	Name           string
+	Description    string
 	ExecFunc       ExecFunc
 	SystemExecFunc SystemExecFunc
 	OutFunc        OutFunc
 	Flags          Flags
 	Options        Options
 	MandatoryFlags []string
+	// Timeout cancels the context of the command when it runs longer
+	Timeout time.Duration
 
 	unnamedFlags map[uint]*Flag
 }
 
+// Exec runs the command unless its context is already done.
 func (c *Command) Exec(ctx SystemContext, flags Flags) (Value, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if c.Timeout <= 0 {
+		return c.exec(ctx, flags)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx.Ctx(), c.Timeout)
+	defer cancel()
+
+	value, err := c.exec(ctx.Copy().WithContext(timeoutCtx), flags)
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		return nil, fmt.Errorf("%w after %s", ErrTimeout, c.Timeout)
+	}
+
+	return value, err
+}
+
+func (c *Command) exec(ctx SystemContext, flags Flags) (value Value, err error) {
+	// a panicking command fails on its own rather than taking the process
+	// with it
+	defer func() {
+		if r := recover(); r != nil {
+			ctx.Logger().WriteMessages("panic in", c.FullPath(), ":", r, string(debug.Stack()))
+			value, err = nil, fmt.Errorf("%w: %v", ErrPanic, r)
+		}
+	}()
+
 	for _, f := range c.MandatoryFlags {
 		if flags.Get(f) == nil {
-			return nil, ErrNoMandatoryFlag
+			return nil, fmt.Errorf("%w: %s", ErrNoMandatoryFlag, f)
 		}
 	}
 
@@ -89,7 +212,16 @@ func (c *Command) Exec(ctx SystemContext, flags Flags) (Value, error) {
 	if c.Type == CommandTypeUser && c.ExecFunc != nil {
 		flagValues := make(FlagValues)
 		for _, flag := range flags {
-			flagValues.Set(flag.Name, flag.Value(ctx))
+			value, err := flag.Value(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			value, err = flag.ValueType.Convert(value)
+			if err != nil {
+				return nil, fmt.Errorf("flag %s: %w", flag.Name, err)
+			}
+			flagValues.Set(flag.Name, value)
 		}
 
 		return c.ExecFunc(ctx, flagValues, c.Options)
@@ -98,6 +230,16 @@ func (c *Command) Exec(ctx SystemContext, flags Flags) (Value, error) {
 	return nil, nil
 }
 
+// FullPath returns the command as it is typed from the root, e.g. ":put" or
+// "/ip route add".
+func (c *Command) FullPath() string {
+	if c.Type == CommandTypeSystem && len(c.Path) == 0 {
+		return ":" + c.Name
+	}
+
+	return "/" + strings.Join(append(append([]string{}, c.Path...), c.Name), " ")
+}
+
 func (c *Command) Out(ctx SystemContext, inFlags Flags) {
 	if c.Type == CommandTypeSystem && c.OutFunc != nil {
 		c.OutFunc(ctx, inFlags, c.Options)
@@ -108,10 +250,12 @@ func (c *Command) Copy() *Command {
 	copied := new(Command)
 	copied.Type = c.Type
 	copied.Name = c.Name
+	copied.Description = c.Description
 	copied.Path = append(copied.Path, c.Path...)
 	copied.SystemExecFunc = c.SystemExecFunc
 	copied.ExecFunc = c.ExecFunc
 	copied.OutFunc = c.OutFunc
+	copied.Timeout = c.Timeout
 	copied.Flags = make(map[string]*Flag)
 	copied.Options = make(map[string]bool)
 
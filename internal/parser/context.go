@@ -24,11 +24,25 @@ type SystemContext interface {
 	VariableTree() *VariableTree
 	GetVariablePayload(name string) interface{}
 	VariableExists(name string) bool
-	GetVariable(name string) Value
+	GetVariable(name string) (Value, error)
 	SetGlobalVariable(name string, value interface{})
 	SetLocalVariable(name string, value interface{})
+	// Jobs is the table of the background jobs shared with the root scope.
+	Jobs() *Jobs
+	// Scheduler runs the tasks of the root scope.
+	Scheduler() *Scheduler
+	// Scripts is the repository of the stored scripts of the root scope.
+	Scripts() *Scripts
+	// Session is the state of the commands in the shell or the runner the
+	// scope belongs to.
+	Session() *Session
+	WithSession(session *Session) SystemContext
+	// User is the owner of the jobs started in the scope.
+	User() string
+	WithUser(user string) SystemContext
 	Ctx() context.Context
 	WithContext(ctx context.Context) SystemContext
+	WithBuffer(buffer terminal.Buffer) SystemContext
 	New() SystemContext
 	Copy() SystemContext
 	Logger() logger.Logger
@@ -41,14 +55,27 @@ type systemContext struct {
 	variableTree *VariableTree
 	logger       logger.Logger
 	buffer       terminal.Buffer
+	jobs         *Jobs
+	scheduler    *Scheduler
+	scripts      *Scripts
+	session      *Session
+	user         string
 }
 
 func newRootContext(ctn di.Container) (SystemContext, error) {
+	ctx := ctn.Get(DefinitionNameContext).(context.Context)
+	clock := ctn.Get(DefinitionNameClock).(Clock)
+	jobs := NewJobs(ctx, clock)
+
 	scope := &systemContext{
-		Context:      ctn.Get(DefinitionNameContext).(context.Context),
+		Context:      ctx,
 		logger:       ctn.Get(logger.DefinitionName).(logger.Logger),
 		buffer:       ctn.Get(terminal.DefinitionNameBuffer).(terminal.Buffer),
 		variableTree: NewVariableTree(),
+		jobs:         jobs,
+		scheduler:    NewScheduler(ctx, clock, jobs),
+		scripts:      NewScripts(clock),
+		session:      NewSession(),
 	}
 
 	list := ctn.Get(DefinitionNameCommandTree).(List)
@@ -78,6 +105,41 @@ func (p *systemContext) WithContext(ctx context.Context) SystemContext {
 	return p
 }
 
+func (p *systemContext) WithBuffer(buffer terminal.Buffer) SystemContext {
+	p.buffer = buffer
+	return p
+}
+
+func (p *systemContext) Jobs() *Jobs {
+	return p.jobs
+}
+
+func (p *systemContext) Scheduler() *Scheduler {
+	return p.scheduler
+}
+
+func (p *systemContext) Scripts() *Scripts {
+	return p.scripts
+}
+
+func (p *systemContext) Session() *Session {
+	return p.session
+}
+
+func (p *systemContext) WithSession(session *Session) SystemContext {
+	p.session = session
+	return p
+}
+
+func (p *systemContext) User() string {
+	return p.user
+}
+
+func (p *systemContext) WithUser(user string) SystemContext {
+	p.user = user
+	return p
+}
+
 func (p *systemContext) Ctx() context.Context {
 	return p.Context
 }
@@ -110,6 +172,11 @@ func (p *systemContext) New() SystemContext {
 		variableTree: p.variableTree.Copy(),
 		logger:       p.logger,
 		buffer:       p.buffer,
+		jobs:         p.jobs,
+		scheduler:    p.scheduler,
+		scripts:      p.scripts,
+		session:      p.session,
+		user:         p.user,
 	}
 }
 
@@ -121,6 +188,11 @@ func (p *systemContext) Copy() SystemContext {
 		variableTree: p.variableTree,
 		logger:       p.logger,
 		buffer:       p.buffer,
+		jobs:         p.jobs,
+		scheduler:    p.scheduler,
+		scripts:      p.scripts,
+		session:      p.session,
+		user:         p.user,
 	}
 }
 
@@ -136,18 +208,18 @@ func (p *systemContext) SetLocalVariable(name string, value interface{}) {
 	p.variableTree.AddLocal(name, value)
 }
 
-func (p *systemContext) GetVariable(name string) Value {
+func (p *systemContext) GetVariable(name string) (Value, error) {
 	payload := p.variableTree.Get(name)
 
 	if payload == nil {
-		return NullValue
+		return NullValue, nil
 	}
 
 	if valuer, ok := payload.(Valuer); ok {
 		return valuer.Value(p)
 	}
 
-	return payload.(Value)
+	return payload.(Value), nil
 }
 
 func (p *systemContext) VariableExists(name string) bool {
@@ -12,6 +12,7 @@ const (
 	DefinitionNameContext     = "global-context"
 	DefinitionNameRootScope   = "parser-global-scope"
 	DefinitionNameCommandTree = "parser-command-tree"
+	DefinitionNameClock       = "parser-clock"
 )
 
 var (
@@ -33,6 +34,12 @@ var (
 			return context.Background(), nil
 		},
 	}
+	DefinitionClock = di.Def{
+		Name: DefinitionNameClock,
+		Build: func(ctn di.Container) (interface{}, error) {
+			return realClock{}, nil
+		},
+	}
 	DefinitionCommandTree = di.Def{
 		Name: DefinitionNameCommandTree,
 		Build: func(ctn di.Container) (interface{}, error) {
@@ -46,6 +53,10 @@ type Parser interface {
 	IsFlushed() bool
 	Add(r models.Rune) (*ParseRuneResponse, error)
 	ParseString(s string) *ParseStringResponse
+	// Exec runs the parsed string, a failure at runtime is an *ExecError.
 	Exec() (*ExecResponse, error)
+	// Cancel cancels the context of the parsed string, it may be called while
+	// Exec runs.
+	Cancel()
 	Continue() *CompleteResponse
 }
@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrTimeout is the failure of a command which has run longer than its
+// Timeout.
+var ErrTimeout = errors.New("timed out")
+
+// ErrNoItem is the failure of a command given a number or an id none of the
+// items it lists has.
+var ErrNoItem = errors.New("no such item")
+
+// Span is a part of the parsed string, counted in runes.
+type Span struct {
+	Offset int
+	Length int
+}
+
+func (s *Span) setStart(offset int) {
+	s.Offset = offset
+}
+
+func (s *Span) setEnd(offset int) {
+	s.Length = offset - s.Offset
+}
+
+// text returns the part of the parsed runes the span covers.
+func (s *Span) text(runes []rune) string {
+	if s.Offset < 0 || s.Length < 0 || s.Offset+s.Length > len(runes) {
+		return ""
+	}
+
+	return string(runes[s.Offset : s.Offset+s.Length])
+}
+
+// spanned is an expression that remembers where it has been parsed.
+type spanned interface {
+	setStart(offset int)
+	setEnd(offset int)
+}
+
+// ExecError is a failure at runtime. Path is the command that failed, it is
+// empty if an expression outside of a command did.
+type ExecError struct {
+	Path string
+	Span Span
+	Err  error
+}
+
+func (e *ExecError) Error() string {
+	var userErr *UserError
+
+	if e.Path == "" || errors.As(e.Err, &userErr) {
+		return e.Err.Error()
+	}
+
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e *ExecError) Unwrap() error {
+	return e.Err
+}
+
+// UserError is raised by a script, its message is shown as it is.
+type UserError struct {
+	Message string
+}
+
+func (e *UserError) Error() string {
+	return e.Message
+}
+
+// SyntaxError is a failure to parse the string, Offset is the rune it has
+// failed at.
+type SyntaxError struct {
+	Offset  int
+	Message string
+	Err     error
+}
+
+func (e *SyntaxError) Error() string {
+	return e.Message
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// syntaxError describes a wrong rune, the parser sets the offset.
+func syntaxError(format string, args ...interface{}) *SyntaxError {
+	return &SyntaxError{Message: fmt.Sprintf(format, args...), Err: ErrWrongRune}
+}
+
+// SyntaxOffset returns the rune a syntax error has occurred at, def if err
+// has no position.
+func SyntaxOffset(err error, def int) int {
+	var syntaxErr *SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return syntaxErr.Offset
+	}
+
+	return def
+}
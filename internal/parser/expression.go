@@ -10,6 +10,7 @@ const (
 	ExpressionTypeMath    = "expression-math"
 	ExpressionTypeVar     = "expression-var"
 	ExpressionTypeStd     = "expression-std"
+	ExpressionTypeArray   = "expression-array"
 )
 
 type Expression interface {
@@ -0,0 +1,259 @@
+package parser
+
+import (
+	"github.com/blkmlk/microshell/internal/models"
+)
+
+type arrayState int
+
+const (
+	arrayStateStart arrayState = iota
+	arrayStateOpen
+	arrayStateElement
+	arrayStateWord
+	arrayStateValue
+	arrayStateNext
+	arrayStateBlock
+	arrayStateClosed
+)
+
+type arrayElement struct {
+	key string
+	exp Expression
+}
+
+// arrayExpression reads the {1;2} and {a=1;b=2} literals. Braces starting
+// with a command hold a command list instead, e.g. the do={...} bodies.
+type arrayExpression struct {
+	Span
+	source   string
+	state    arrayState
+	elements []*arrayElement
+	block    Expression
+	word     []models.Rune
+	key      string
+	decided  bool
+	opened   int
+	quoted   bool
+}
+
+func NewArrayExpression() Expression {
+	return &arrayExpression{}
+}
+
+func (a *arrayExpression) setSource(text []rune) {
+	span := a.Span
+	if a.state == arrayStateClosed {
+		// the closing brace is handed back to the parent
+		span.Length++
+	}
+
+	a.source = span.text(text)
+}
+
+func (a *arrayExpression) Type() ExpressionType {
+	if a.block != nil {
+		return a.block.Type()
+	}
+
+	return ExpressionTypeArray
+}
+
+func (a *arrayExpression) Complete(ctx SystemContext) *CompleteResponse {
+	if a.block != nil {
+		return a.block.Complete(ctx)
+	}
+
+	return &CompleteResponse{}
+}
+
+func (a *arrayExpression) Add(ctx SystemContext, r models.Rune) *Response {
+	var resp = NewResponse().WithAction(ResponseGoNext)
+
+	switch a.state {
+	case arrayStateStart:
+		if !r.Is('{') {
+			return resp.WithError(ErrWrongRune)
+		}
+
+		a.state = arrayStateOpen
+		return resp.WithObject(ObjectCurlyBrackets)
+	case arrayStateBlock:
+		// the closing brace comes back from the command list
+		a.state = arrayStateClosed
+		return resp.WithAction(ResponseGoOut)
+	case arrayStateClosed:
+		return resp.WithError(ErrWrongRune)
+	case arrayStateWord:
+		if r.IsAlpha() || r.IsUnicodeLetter() || r.IsNumber() || r.Is('-') || r.Is('_') {
+			a.word = append(a.word, r)
+			return resp.WithObject(ObjectValue)
+		}
+
+		return a.handleWord(ctx, r)
+	}
+
+	// the closing brackets and quotes of the elements come back here
+	switch {
+	case a.opened > 0 && (r.Is(')') || r.Is(']') || r.Is('}')):
+		a.opened--
+		a.state = arrayStateNext
+		return resp.WithObject(bracketObject(r))
+	case a.quoted && r.Is('"'):
+		a.quoted = false
+		a.state = arrayStateNext
+		return resp.WithObject(ObjectQuotedSymbol)
+	}
+
+	switch {
+	case r.IsSpace():
+		if a.state == arrayStateValue {
+			return resp.WithError(ErrWrongRune)
+		}
+
+		return resp.WithObject(ObjectSpace)
+	case r.Is(';'):
+		switch a.state {
+		case arrayStateOpen, arrayStateElement:
+		case arrayStateNext:
+			a.state = arrayStateElement
+		default:
+			return resp.WithError(ErrWrongRune)
+		}
+
+		return resp.WithObject(ObjectOperator)
+	case r.Is('}'):
+		if a.state == arrayStateValue {
+			return resp.WithError(ErrWrongRune)
+		}
+
+		a.state = arrayStateClosed
+		return resp.WithAction(ResponseGoOut).WithObject(ObjectCurlyBrackets)
+	case a.state == arrayStateNext:
+		return resp.WithError(ErrWrongRune)
+	case r.Is(':') || r.Is('/'):
+		if a.state != arrayStateOpen {
+			return resp.WithError(ErrWrongRune)
+		}
+
+		return a.toBlock(resp)
+	case r.IsAlpha() || r.IsUnicodeLetter():
+		if a.state == arrayStateValue {
+			return a.addElement(NewStdExpression(false), resp)
+		}
+
+		a.word = append(a.word[:0], r)
+		a.state = arrayStateWord
+		return resp.WithObject(ObjectValue)
+	case r.IsNumber() || r.Is('-'):
+		return a.addElement(NewStdExpression(false), resp)
+	case r.Is('"'):
+		a.quoted = true
+		return a.addElement(NewStdExpression(false), resp)
+	case r.Is('$'):
+		return a.addElement(NewVariable(false), resp)
+	case r.Is('('):
+		a.opened++
+		return a.addElement(NewMathExpression(), resp)
+	case r.Is('['):
+		a.opened++
+		return a.addElement(NewCommandList(false, false), resp)
+	case r.Is('{'):
+		a.opened++
+		return a.addElement(NewArrayExpression(), resp)
+	}
+
+	return resp.WithError(ErrWrongRune)
+}
+
+// handleWord decides what the word read so far is. A key is followed by an
+// equal sign, a word opening the braces is a command unless it is a bool.
+func (a *arrayExpression) handleWord(ctx SystemContext, r models.Rune) *Response {
+	var resp = NewResponse().WithAction(ResponseGoNext)
+
+	if r.Is('=') {
+		a.key = string(a.word)
+		a.word = a.word[:0]
+		a.decided = true
+		a.state = arrayStateValue
+		return resp.WithObject(ObjectEqualSymbol)
+	}
+
+	word := string(a.word)
+
+	if !a.decided && word != "true" && word != "false" {
+		return a.toBlock(resp)
+	}
+
+	exp := NewStdExpression(false)
+	for _, w := range a.word {
+		exp.Add(ctx, w)
+	}
+
+	a.word = a.word[:0]
+	a.elements = append(a.elements, &arrayElement{key: a.key, exp: exp})
+	a.key = ""
+	a.state = arrayStateNext
+
+	return a.Add(ctx, r)
+}
+
+// toBlock hands the braces over to a command list.
+func (a *arrayExpression) toBlock(resp *Response) *Response {
+	replay := append([]models.Rune{'{'}, a.word...)
+
+	a.block = NewCommandList(false, true)
+	a.word = nil
+	a.state = arrayStateBlock
+
+	return resp.WithAction(ResponseRepeat).WithExpression(a.block).WithReplay(replay...)
+}
+
+func (a *arrayExpression) addElement(exp Expression, resp *Response) *Response {
+	a.elements = append(a.elements, &arrayElement{key: a.key, exp: exp})
+	a.key = ""
+	a.decided = true
+	a.state = arrayStateNext
+
+	return resp.WithAction(ResponseRepeat).WithExpression(exp)
+}
+
+func (a *arrayExpression) Close(ctx SystemContext) *CloseResponse {
+	var resp CloseResponse
+
+	if a.state != arrayStateClosed {
+		resp.UnclosedBrackets = '{'
+		resp.Error = ErrNotFinished
+	}
+
+	return &resp
+}
+
+func (a *arrayExpression) Value(ctx SystemContext) (Value, error) {
+	if a.block != nil {
+		return a.block.Value(ctx)
+	}
+
+	items := make([]ArrayItem, 0, len(a.elements))
+	for _, e := range a.elements {
+		value, err := e.exp.Value(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, ArrayItem{Key: e.key, Value: value})
+	}
+
+	return newArray(items), nil
+}
+
+func bracketObject(r models.Rune) Object {
+	switch {
+	case r.Is('(') || r.Is(')'):
+		return ObjectRoundBrackets
+	case r.Is('{') || r.Is('}'):
+		return ObjectCurlyBrackets
+	default:
+		return ObjectSquareBrackets
+	}
+}
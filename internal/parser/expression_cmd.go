@@ -3,6 +3,7 @@ package parser
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/blkmlk/microshell/internal/models"
 )
@@ -30,9 +31,14 @@ const (
 )
 
 type commandExpression struct {
+	Span
+
 	relativeRoot *CommandTree
-	flagTree     *CommandTree
-	iterator     *commandIterator
+	// menu is the tree of the command, the commands in its brackets are
+	// looked up from it
+	menu     *CommandTree
+	flagTree *CommandTree
+	iterator *commandIterator
 
 	setRelativeRoot bool
 	state           StateCommand
@@ -41,6 +47,9 @@ type commandExpression struct {
 
 	// path + command
 	currentCommand *Command
+	// path and word are the menus and the name typed so far
+	path []string
+	word []models.Rune
 
 	// flag
 	currentFlag         *Flag
@@ -55,9 +64,15 @@ type commandExpression struct {
 }
 
 func NewCommandExpression(ctx SystemContext) Expression {
+	return newCommandExpression(ctx.CommandRoot())
+}
+
+// newCommandExpression returns a command looked up from the menu root.
+func newCommandExpression(root *CommandTree) *commandExpression {
 	return &commandExpression{
-		relativeRoot: ctx.CommandRoot(),
-		iterator:     ctx.CommandRoot().GetIterator(),
+		relativeRoot: root,
+		menu:         root,
+		iterator:     root.GetIterator(),
 		state:        StateCommandStart,
 		flags:        make(Flags),
 	}
@@ -67,30 +82,45 @@ func (c *commandExpression) Type() ExpressionType {
 	return ExpressionTypeCmd
 }
 
-func (c *commandExpression) Value(ctx SystemContext) Value {
+func (c *commandExpression) Value(ctx SystemContext) (Value, error) {
 	switch c.state {
 	case StateCommandStart, StateCommandPath:
-		return NullValue
+		return NullValue, nil
 	default:
 		if c.currentCommand == nil {
-			return NullValue
+			return NullValue, nil
 		}
 
 		value, err := c.currentCommand.Exec(ctx, c.flags)
 
 		if err != nil {
-			// TODO: handle err
-			return nil
+			return nil, c.execError(err)
 		}
 
 		if value == nil {
 			value = NullValue
 		}
 
-		return value
+		return value, nil
 	}
 }
 
+// execError returns the failure of the command. A failure of a nested
+// command is kept as it is, a failed expression keeps its own span.
+func (c *commandExpression) execError(err error) error {
+	var execErr *ExecError
+
+	if !errors.As(err, &execErr) {
+		return &ExecError{Path: c.currentCommand.FullPath(), Span: c.Span, Err: err}
+	}
+
+	if execErr.Path == "" {
+		execErr.Path = c.currentCommand.FullPath()
+	}
+
+	return err
+}
+
 func (c *commandExpression) Complete(ctx SystemContext) *CompleteResponse {
 	if c.iterator == nil {
 		return nil
@@ -127,8 +157,12 @@ func (c *commandExpression) Add(ctx SystemContext, r models.Rune) *Response {
 		resp = c.handleSemicolon(ctx)
 	case r.Is('='):
 		resp = c.handleEqual()
-	case r.IsLowerAlpha():
+	case r.IsLowerAlpha() || r.IsUnicodeLetter():
 		resp = c.handleLowerAlpha(ctx, r)
+	case r.Is('-'):
+		resp = c.handleDash(ctx, r)
+	case r.Is('*'):
+		resp = c.handleAsterisk(ctx)
 	case r.IsNumber():
 		resp = c.handleNumber(ctx)
 	case r.Is('"'):
@@ -184,21 +218,32 @@ func (c *commandExpression) Close(ctx SystemContext) *CloseResponse {
 		c.flags.Set(c.currentFlag)
 	case StateCommandOption:
 		c.currentCommand.Options.Set(c.iterator.Value())
-	case StateFlagEqual, StateCommandFlag:
+	case StateFlagEqual:
+		resp.Error = &SyntaxError{Message: "expected value after `=`", Err: ErrNotFinished}
+	case StateCommandFlag:
 		resp.Error = ErrNotFinished
 	}
 
 	return &resp
 }
 
+// handleSlash starts an absolute path, or a file path after =, e.g.
+// print file=/tmp/out.txt.
 func (c *commandExpression) handleSlash(ctx SystemContext) *Response {
 	var resp = NewResponse().WithAction(ResponseGoNext)
 
+	if c.state == StateFlagEqual && c.currentFlag.ValueType == ValueTypeString {
+		c.state = StateFlagValue
+		resp.WithObject(ObjectValue)
+		return c.addFlag(NewStdExpression(false), resp)
+	}
+
 	if c.state != StateCommandStart || c.started {
 		return resp.WithError(ErrWrongRune)
 	}
 
 	c.iterator = ctx.CommandTree().GetIterator()
+	c.menu = ctx.CommandTree()
 	c.started = true
 	return resp.WithObject(ObjectPath)
 }
@@ -249,21 +294,33 @@ func (c *commandExpression) handleSpace(ctx SystemContext) *Response {
 	switch c.state {
 	case StateCommandPath:
 		if !c.iterator.GoToEnd() {
-			return resp.WithError(ErrWrongRune)
+			return resp.WithError(c.unknownCommand(' '))
 		}
 		nextTree := c.iterator.NextTree()
 		if nextTree == nil {
 			return resp.WithError(ErrPanic)
 		}
+		c.path = append(c.path, c.iterator.Value())
+		c.word = c.word[:0]
+		c.menu = nextTree
 		c.iterator = nextTree.GetIterator()
 		c.state = StateCommandStart
 	case StateCommandCommand:
 		if !c.iterator.GoToEnd() {
-			return resp.WithError(ErrWrongRune)
+			return resp.WithError(c.unknownCommand(' '))
 		}
 		c.state = StateCommandArgument
 		return c.closeCommand(resp).WithObject(ObjectSpace)
 	case StateCommandFlag:
+		if flag := c.queryFlag(); flag != nil {
+			c.currentFlag = flag.Copy()
+			c.unnamedFlagValue = c.unnamedFlagValue[:0]
+			c.iterator = c.flagTree.GetIterator()
+			c.state = StateFlagValue
+			c.flagUsed = true
+			return c.addFlag(NewQueryExpression(flag.Fields), resp).WithObject(ObjectSpace)
+		}
+
 		return c.checkUnnamedFlag(ctx, NewStdExpression(false), resp)
 	case StateFlagValue:
 		c.flags.Set(c.currentFlag)
@@ -281,7 +338,7 @@ func (c *commandExpression) handleSpace(ctx SystemContext) *Response {
 		c.iterator = c.flagTree.GetIterator()
 		c.state = StateCommandArgument
 	case StateFlagEqual:
-		return resp.WithError(ErrWrongRune)
+		return resp.WithError(syntaxError("expected value after `=`"))
 	}
 
 	return resp.WithObject(ObjectSpace)
@@ -291,11 +348,14 @@ func (c *commandExpression) handleLowerAlpha(ctx SystemContext, r models.Rune) *
 	var resp = NewResponse().WithAction(ResponseGoNext)
 
 	switch c.state {
-	case StateCommandStart:
+	case StateCommandStart, StateCommandPath, StateCommandCommand:
 		if !c.iterator.GoNext(r) {
-			return resp.WithError(ErrWrongRune)
+			return resp.WithError(c.unknownCommand(r))
 		}
 
+		c.word = append(c.word, r)
+
+		// a path and a command may share the first letters, e.g. ip and if
 		options := c.iterator.NextOptions()
 
 		if options.AggregatedLevel == LevelTypeCommand {
@@ -305,16 +365,6 @@ func (c *commandExpression) handleLowerAlpha(ctx SystemContext, r models.Rune) *
 			c.state = StateCommandPath
 			resp.WithObject(ObjectPath)
 		}
-	case StateCommandPath:
-		if !c.iterator.GoNext(r) {
-			return resp.WithError(ErrWrongRune)
-		}
-		resp.WithObject(ObjectPath)
-	case StateCommandCommand:
-		if !c.iterator.GoNext(r) {
-			return resp.WithError(ErrWrongRune)
-		}
-		resp.WithObject(ObjectCommand)
 	case StateCommandArgument:
 		if c.prevRune.IsSpace() {
 			c.unnamedFlagPosition++
@@ -362,6 +412,28 @@ func (c *commandExpression) handleLowerAlpha(ctx SystemContext, r models.Rune) *
 	return resp
 }
 
+// handleDash continues a name, e.g. on-error, or starts a negative number.
+func (c *commandExpression) handleDash(ctx SystemContext, r models.Rune) *Response {
+	switch c.state {
+	case StateCommandPath, StateCommandCommand, StateCommandFlag, StateCommandOption:
+		return c.handleLowerAlpha(ctx, r)
+	case StateCommandArgument, StateFlagEqual:
+		return c.handleNumber(ctx)
+	}
+
+	return NewResponse().WithError(ErrWrongRune)
+}
+
+// handleAsterisk starts an internal id, e.g. *1A.
+func (c *commandExpression) handleAsterisk(ctx SystemContext) *Response {
+	switch c.state {
+	case StateCommandArgument, StateFlagEqual:
+		return c.handleNumber(ctx)
+	}
+
+	return NewResponse().WithError(ErrWrongRune)
+}
+
 func (c *commandExpression) handleNumber(ctx SystemContext) *Response {
 	var resp = NewResponse().WithAction(ResponseGoNext)
 
@@ -373,18 +445,12 @@ func (c *commandExpression) handleNumber(ctx SystemContext) *Response {
 		resp.WithObject(ObjectValue)
 		return c.checkUnnamedFlag(ctx, NewStdExpression(false), resp)
 	case StateFlagEqual:
-		switch c.currentFlag.ValueType {
-		case ValueTypeString:
-			c.state = StateFlagValue
-			resp.WithObject(ObjectValue)
-			return c.addFlag(NewStdExpression(false), resp)
-		case ValueTypeNumber:
-			c.state = StateFlagValue
-			resp.WithObject(ObjectValue)
-			return c.addFlag(NewStdExpression(false), resp)
-		default:
+		if c.currentFlag.ValueType == ValueTypeBool {
 			return resp.WithError(ErrWrongRune)
 		}
+		c.state = StateFlagValue
+		resp.WithObject(ObjectValue)
+		return c.addFlag(NewStdExpression(false), resp)
 	}
 
 	return resp
@@ -441,16 +507,12 @@ func (c *commandExpression) handleVariable(ctx SystemContext) *Response {
 func (c *commandExpression) handleEqual() *Response {
 	var resp = NewResponse().WithAction(ResponseGoNext)
 
-	if !c.iterator.GoToEnd() {
-		return resp.WithError(ErrWrongRune)
-	}
-
-	if c.iterator.Level() != LevelTypeFlag {
-		return resp.WithError(ErrWrongRune)
+	if c.state == StateFlagEqual {
+		return resp.WithError(syntaxError("expected value after `=`"))
 	}
 
-	if c.state != StateCommandFlag {
-		return resp.WithError(ErrWrongRune)
+	if !c.iterator.GoToEnd() || c.iterator.Level() != LevelTypeFlag || c.state != StateCommandFlag {
+		return resp.WithError(syntaxError("expected flag name before `=`"))
 	}
 
 	flag, ok := c.iterator.Payload().(*Flag)
@@ -480,11 +542,11 @@ func (c *commandExpression) handleCommandList(ctx SystemContext, r models.Rune)
 			}
 			c.opened++
 
-			return c.checkUnnamedFlag(ctx, NewCommandList(false, isCurly), resp)
+			return c.checkUnnamedFlag(ctx, c.newBracketExpression(isCurly), resp)
 		case StateFlagEqual:
 			c.state = StateFlagValue
 			c.opened++
-			return c.addFlag(NewCommandList(false, isCurly), resp)
+			return c.addFlag(c.newBracketExpression(isCurly), resp)
 		default:
 			return resp.WithError(ErrWrongRune)
 		}
@@ -502,10 +564,13 @@ func (c *commandExpression) handleCommandList(ctx SystemContext, r models.Rune)
 			c.currentCommand.Options.Set(c.iterator.Value())
 		}
 
-		// we don't need to decrease the counter to go next
+		// the closing bracket of a flag value comes back from the inner
+		// expression, an unpaired one ends the command
 		if c.opened == 0 {
 			return c.goOut(ctx, resp)
 		}
+
+		c.opened--
 	}
 
 	return resp
@@ -544,15 +609,32 @@ func (c *commandExpression) handleMath(ctx SystemContext, r models.Rune) *Respon
 			c.currentCommand.Options.Set(c.iterator.Value())
 		}
 
-		// we don't need to decrease the counter to go next
+		// the closing bracket of a flag value comes back from the inner
+		// expression, an unpaired one ends the command
 		if c.opened == 0 {
 			return c.goOut(ctx, resp)
 		}
+
+		c.opened--
 	}
 
 	return resp
 }
 
+// newBracketExpression returns the value in brackets, braces hold an array
+// or a deferred command list. The commands in brackets are looked up from
+// the menu of the command, e.g. find in /ip service set [find name=api].
+func (c *commandExpression) newBracketExpression(isCurly bool) Expression {
+	if isCurly {
+		return NewArrayExpression()
+	}
+
+	list := NewCommandList(false, false).(*commandList)
+	list.root = c.menu
+
+	return list
+}
+
 func (c *commandExpression) closeCommand(resp *Response) *Response {
 	cmd, ok := c.iterator.Payload().(*Command)
 	if !ok {
@@ -575,17 +657,22 @@ func (c *commandExpression) goOut(ctx SystemContext, resp *Response) *Response {
 }
 
 func (c *commandExpression) checkUnnamedFlag(ctx SystemContext, exp Expression, resp *Response) *Response {
-	if c.flagUsed {
-		return resp.WithError(ErrWrongRune)
+	var flag *Flag
+	if !c.flagUsed {
+		flag = c.currentCommand.UnnamedFlag(c.unnamedFlagPosition)
 	}
 
-	flag := c.currentCommand.UnnamedFlag(c.unnamedFlagPosition)
 	if flag == nil {
-		return resp.WithError(ErrWrongRune)
+		if len(c.flagTree.GetIterator().NextOptions().Options) == 0 {
+			return resp.WithError(syntaxError("expected end of command"))
+		}
+
+		return resp.WithError(syntaxError("expected flag name or `=`"))
 	}
 
 	c.currentFlag = flag.Copy()
 	c.currentFlag.Set(exp)
+	resp.WithSecret(c.currentFlag.Secret)
 
 	for _, r := range c.unnamedFlagValue {
 		exp.Add(ctx, r)
@@ -597,7 +684,36 @@ func (c *commandExpression) checkUnnamedFlag(ctx SystemContext, exp Expression,
 	return resp.WithAction(ResponseRepeat).WithExpression(exp).WithObject(ObjectValue)
 }
 
+// queryFlag returns the flag written if it takes the rest of the command,
+// e.g. print where port>1000.
+func (c *commandExpression) queryFlag() *Flag {
+	if !c.iterator.GoToEnd() || c.iterator.Level() != LevelTypeFlag {
+		return nil
+	}
+
+	flag, ok := c.iterator.Payload().(*Flag)
+	if !ok || !flag.Query {
+		return nil
+	}
+
+	return flag
+}
+
+// unknownCommand describes the name which isn't in the menu.
+func (c *commandExpression) unknownCommand(r models.Rune) *SyntaxError {
+	word := string(c.word)
+	if !r.IsSpace() {
+		word += r.String()
+	}
+
+	if len(c.path) == 0 {
+		return syntaxError("unknown command `%s`", word)
+	}
+
+	return syntaxError("unknown command `%s` under /%s", word, strings.Join(c.path, " "))
+}
+
 func (c *commandExpression) addFlag(exp Expression, resp *Response) *Response {
 	c.currentFlag.Set(exp)
-	return resp.WithAction(ResponseRepeat).WithExpression(exp)
+	return resp.WithAction(ResponseRepeat).WithExpression(exp).WithSecret(c.currentFlag.Secret)
 }
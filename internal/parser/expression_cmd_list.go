@@ -5,6 +5,8 @@ import (
 )
 
 type commandList struct {
+	Span
+	source          string
 	expressions     []Expression
 	innerExpression Expression
 	value           Value
@@ -12,6 +14,9 @@ type commandList struct {
 	listRune        models.Rune
 	usedRunes       map[models.Rune]int
 	closed          bool
+	// root is the menu the commands are looked up from, the command root of
+	// the scope if it is nil
+	root *CommandTree
 }
 
 func NewCommandList(rootMode, isCurly bool) Expression {
@@ -33,12 +38,44 @@ func NewCommandList(rootMode, isCurly bool) Expression {
 	return cl
 }
 
+// Source returns the text the expression has been parsed from, it is empty
+// for anything but a code block or an array.
+func Source(e Expression) string {
+	switch t := e.(type) {
+	case *commandList:
+		return t.source
+	case *arrayExpression:
+		return t.source
+	}
+
+	return ""
+}
+
+func (c *commandList) setSource(text []rune) {
+	span := c.Span
+	if c.closed {
+		// the closing bracket is handed back to the parent
+		span.Length++
+	}
+
+	c.source = span.text(text)
+}
+
 func (c *commandList) Type() ExpressionType {
 	return ExpressionTypeCmdList
 }
 
 func (c *commandList) Complete(ctx SystemContext) *CompleteResponse {
-	return NewCommandExpression(ctx).Complete(ctx)
+	return c.newCommand(ctx).Complete(ctx)
+}
+
+// newCommand returns the next command of the list.
+func (c *commandList) newCommand(ctx SystemContext) Expression {
+	if c.root != nil {
+		return newCommandExpression(c.root)
+	}
+
+	return NewCommandExpression(ctx)
 }
 
 func (c *commandList) Add(ctx SystemContext, r models.Rune) *Response {
@@ -57,7 +94,7 @@ func (c *commandList) Add(ctx SystemContext, r models.Rune) *Response {
 		return resp.WithAction(ResponseGoNext).WithObject(ObjectOperator)
 	case r.Is('/') || r.Is(':') || r.IsLowerAlpha():
 		if c.innerExpression == nil {
-			c.innerExpression = NewCommandExpression(ctx)
+			c.innerExpression = c.newCommand(ctx)
 		}
 
 		resp.WithAction(ResponseRepeat).WithExpression(c.innerExpression)
@@ -101,7 +138,8 @@ func (c *commandList) Close(ctx SystemContext) *CloseResponse {
 	return resp
 }
 
-func (c *commandList) Value(ctx SystemContext) Value {
+// Value runs the commands one by one, the first failure stops the list.
+func (c *commandList) Value(ctx SystemContext) (Value, error) {
 	var value Value
 
 	if c.listRune.Is('{') {
@@ -109,15 +147,14 @@ func (c *commandList) Value(ctx SystemContext) Value {
 	}
 
 	for _, expr := range c.expressions {
-		value = expr.Value(ctx)
+		var err error
 
-		if _, ok := value.(error); ok {
-			// TODO: handle err
-			return NullValue
+		if value, err = expr.Value(ctx); err != nil {
+			return nil, err
 		}
 	}
 
-	return value
+	return value, nil
 }
 
 func (c *commandList) openRune(r models.Rune) *Response {
@@ -131,7 +168,9 @@ func (c *commandList) openRune(r models.Rune) *Response {
 		var ctxType ContextType
 		switch r {
 		case '[':
-			c.innerExpression = NewCommandList(false, false)
+			list := NewCommandList(false, false).(*commandList)
+			list.root = c.root
+			c.innerExpression = list
 			ctxType = ContextTypeCopied
 		case '{':
 			c.innerExpression = NewCommandList(false, true)
@@ -24,10 +24,17 @@ type CommandListExpressionTestSuite struct {
 }
 
 func execSetVar(ctx SystemContext, flags Flags, options Options) (Value, error) {
-	name := flags.Get("name").Value(ctx)
-	value := flags.Get("value")
+	name, err := flags.Get("name").Value(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := flags.Get("value").Value(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	ctx.SetLocalVariable(name.String(), value.Value(ctx))
+	ctx.SetLocalVariable(name.String(), value)
 
 	return NullValue, nil
 }
@@ -97,6 +104,7 @@ func (t *CommandListExpressionTestSuite) SetupTest() {
 	err = builder.Add(
 		Definition,
 		DefinitionContext,
+		DefinitionClock,
 		DefinitionScope,
 		logger.Definition,
 		listDefinition,
@@ -141,8 +149,9 @@ func (t *CommandListExpressionTestSuite) TestCommandList() {
 	varTest.On("Value", mocks.AnyArgument).
 		Run(func(args mock.Arguments) {
 			ctx := args.Get(0).(SystemContext)
-			v := ctx.GetVariable("var").String()
-			t.Require().Equal("123", v)
+			v, err := ctx.GetVariable("var")
+			t.Require().NoError(err)
+			t.Require().Equal("123", v.String())
 		}).Return(NullValue, nil).Once()
 	t.ctx.SetGlobalVariable("test", varTest)
 	t.runTest("[/set ab 123];[$test var=$ab]", nil, 0)
@@ -158,10 +167,11 @@ func (t *CommandListExpressionTestSuite) TestCommandList() {
 	f.On("Value", mocks.AnyArgument).
 		Run(func(args mock.Arguments) {
 			ctx := args.Get(0).(SystemContext)
-			v := ctx.GetVariable("var2").String()
-			t.Require().Equal("123", v)
+			v, err := ctx.GetVariable("var2")
+			t.Require().NoError(err)
+			t.Require().Equal("123", v.String())
 			invoked = true
-		}).Return(NullValue).Once()
+		}).Return(NullValue, nil).Once()
 
 	t.ctx.SetGlobalVariable("hello", f)
 
@@ -174,7 +184,7 @@ func (t *CommandListExpressionTestSuite) TestCommandList() {
 	f.On("Value", mocks.AnyArgument).
 		Run(func(args mock.Arguments) {
 			invoked = true
-		}).Return(NullValue).Once()
+		}).Return(NullValue, nil).Once()
 	t.ctx.SetGlobalVariable("hello", f)
 	t.runTest("{$hello}", nil, 0)
 	t.Require().True(invoked)
@@ -183,10 +193,11 @@ func (t *CommandListExpressionTestSuite) TestCommandList() {
 	f.On("Value", mocks.AnyArgument).
 		Run(func(args mock.Arguments) {
 			ctx := args.Get(0).(SystemContext)
-			v := ctx.GetVariable("f").String()
-			t.Require().Equal("10", v)
+			v, err := ctx.GetVariable("f")
+			t.Require().NoError(err)
+			t.Require().Equal("10", v.String())
 			invoked = true
-		}).Return(NullValue).Once()
+		}).Return(NullValue, nil).Once()
 	t.ctx.SetGlobalVariable("hello", f)
 	t.runTest("{/set var3 10; $hello f=$var3}", nil, 0)
 	t.Require().True(invoked)
@@ -195,10 +206,11 @@ func (t *CommandListExpressionTestSuite) TestCommandList() {
 	f.On("Value", mocks.AnyArgument).
 		Run(func(args mock.Arguments) {
 			ctx := args.Get(0).(SystemContext)
-			v := ctx.GetVariable("f").String()
-			t.Require().Equal("", v)
+			v, err := ctx.GetVariable("f")
+			t.Require().NoError(err)
+			t.Require().Equal("", v.String())
 			invoked = true
-		}).Return(NullValue).Once()
+		}).Return(NullValue, nil).Once()
 	t.ctx.SetGlobalVariable("hello", f)
 	t.runTest("{/set var4 10};[$hello f=$var4]", nil, 0)
 	t.Require().True(invoked)
@@ -219,7 +231,7 @@ func (t *CommandListExpressionTestSuite) runTest(command string, expectedError e
 		t.Require().NoError(err)
 	} else {
 		t.Require().Error(err)
-		t.Require().Equal(expectedError, err)
+		t.Require().ErrorIs(err, expectedError)
 	}
 
 	t.Require().Equal(count, invoked)
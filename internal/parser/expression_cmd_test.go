@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 	"github.com/blkmlk/microshell/internal/terminal"
 	"testing"
@@ -22,6 +23,7 @@ type CommandExpressionTestSuite struct {
 	ctx    SystemContext
 	root   *CommandTree
 	exec   *MockTestExec
+	where  *Flag
 }
 
 type expectedValue struct {
@@ -63,6 +65,58 @@ func (t *CommandExpressionTestSuite) SetupTest() {
 						"verbose": false,
 					},
 				},
+				{
+					Path:     []string{"ip", "route"},
+					Name:     "add",
+					Type:     CommandTypeUser,
+					ExecFunc: t.exec.Exec,
+					Flags: map[string]*Flag{
+						"dst": {
+							Name:      "dst",
+							Mandatory: true,
+							Number:    1,
+							ValueType: ValueTypeIPPrefix,
+						},
+						"gateway": {
+							Name:      "gateway",
+							ValueType: ValueTypeIP,
+						},
+						"nexthop": {
+							Name:      "nexthop",
+							ValueType: ValueTypeIP6,
+						},
+						"timeout": {
+							Name:      "timeout",
+							ValueType: ValueTypeTime,
+						},
+					},
+				},
+				{
+					Path: []string{"ip", "route"},
+					Name: "crash",
+					Type: CommandTypeUser,
+					ExecFunc: func(ctx Context, flags FlagValues, options Options) (Value, error) {
+						var values map[string]Value
+						values["crash"] = NullValue
+						return nil, nil
+					},
+				},
+				{
+					Path: []string{"ip", "route"},
+					Name: "find",
+					Type: CommandTypeSystem,
+					SystemExecFunc: func(ctx SystemContext, flags Flags, options Options) (Value, error) {
+						t.where = flags.Get("where")
+						return NullValue, nil
+					},
+					Flags: map[string]*Flag{
+						"where": {
+							Name:   "where",
+							Query:  true,
+							Fields: []string{"dst", "gateway", "distance", "disabled", "comment"},
+						},
+					},
+				},
 			}}, nil
 		},
 	}
@@ -73,6 +127,7 @@ func (t *CommandExpressionTestSuite) SetupTest() {
 	err = builder.Add(
 		Definition,
 		DefinitionContext,
+		DefinitionClock,
 		DefinitionScope,
 		logger.Definition,
 		listDefinition,
@@ -248,6 +303,160 @@ func (t *CommandExpressionTestSuite) TestExpression() {
 	t.runTest(fmt.Sprintf("/ip firewall  add  \"%s\" verbose verb;", networkValue), ErrWrongRune, nil)
 }
 
+func (t *CommandExpressionTestSuite) TestTypedFlags() {
+	t.runTest("/ip route add 10.0.0.0/8 gateway=10.0.0.1 timeout=1h30m;", nil, []*expectedValue{
+		{
+			Flags: map[string]string{
+				"dst":     "10.0.0.0/8",
+				"gateway": "10.0.0.1",
+				"timeout": "01:30:00",
+			},
+		},
+	})
+	t.runTest("/ip route add dst=192.168.1.5/24 nexthop=fe80::1 timeout=30;", nil, []*expectedValue{
+		{
+			Flags: map[string]string{
+				"dst":     "192.168.1.5/24",
+				"nexthop": "fe80::1",
+				"timeout": "00:00:30",
+			},
+		},
+	})
+	t.runTest("/ip route add dst=(10.0.0.0/8) gateway=(10.0.0.1 + 1);", nil, []*expectedValue{
+		{
+			Flags: map[string]string{
+				"dst":     "10.0.0.0/8",
+				"gateway": "10.0.0.2",
+			},
+		},
+	})
+
+	// the values of another kind don't reach the command
+	for _, command := range []string{
+		"/ip route add 10.0.0.1;",
+		"/ip route add 10.0.0.0/8 gateway=fe80::1;",
+		"/ip route add 10.0.0.0/8 nexthop=10.0.0.1;",
+		"/ip route add 10.0.0.0/8 timeout=soon;",
+	} {
+		err := t.buildExpression(command)
+		t.Require().ErrorIs(err, ErrWrongType, command)
+
+		var execErr *ExecError
+		t.Require().ErrorAs(err, &execErr)
+		t.Require().Equal("/ip route add", execErr.Path)
+	}
+}
+
+type testProperties map[string]Value
+
+func (p testProperties) Property(name string) (Value, bool) {
+	v, ok := p[name]
+	return v, ok
+}
+
+func (t *CommandExpressionTestSuite) TestQuery() {
+	route := testProperties{
+		"dst":      ParseValue("10.1.0.0/16"),
+		"gateway":  ParseValue("10.0.0.1"),
+		"distance": NewNumberValue(2),
+		"disabled": NewBoolValue(false),
+		"comment":  NewStringValue("uplink"),
+	}
+
+	tests := []struct {
+		query    string
+		expected bool
+	}{
+		{"distance>1 and gateway=10.0.0.1", true},
+		{"distance>1 and not (gateway=10.0.0.1)", false},
+		{"distance=1 or disabled=no", true},
+		{"not disabled", true},
+		{"dst in 10.0.0.0/8 and comment~\"^up\"", true},
+		{"(distance + 1) = 3", true},
+		{"distance > 5", false},
+	}
+
+	for _, test := range tests {
+		t.where = nil
+		t.Require().NoError(t.buildExpression("/ip route find where "+test.query), test.query)
+		t.Require().NotNil(t.where, test.query)
+
+		value, err := t.where.Value(WithProperties(t.ctx, route))
+		t.Require().NoError(err, test.query)
+		t.Require().Equal(test.expected, IsYes(value), test.query)
+	}
+
+	// the query ends with the command
+	for _, command := range []string{
+		"/ip route find where distance=2; /ip route find",
+		"/ip route find where ([/ip route find where distance=2] = nil)",
+	} {
+		t.Require().NoError(t.buildExpression(command), command)
+	}
+
+	t.Require().Error(t.buildExpression("/ip route find where distance>"))
+	t.Require().Error(t.buildExpression("/ip route find where (distance>1"))
+
+	// a name out of a comparison must be a field
+	for _, query := range []string{"bogus=1", "distance>1 and bogus", "not bogus", "(bogus=1)", "disabled or (bogus)"} {
+		err := t.buildExpression("/ip route find where " + query)
+		t.Require().ErrorIs(err, ErrWrongRune, query)
+		t.Require().Contains(err.Error(), "unknown field `bogus`", query)
+	}
+
+	t.Require().NoError(t.buildExpression("/ip route find where gateway=bogus"))
+
+	t.parser.Flush()
+	t.Require().NoError(t.parser.ParseString("/ip route find where di").Error)
+	resp := t.parser.Continue()
+	t.Require().Len(resp.Options, 2)
+	t.Require().Equal("disabled", resp.Options[0].Option)
+	t.Require().Equal("distance", resp.Options[1].Option)
+	t.Require().Equal("s", resp.Merged)
+
+	t.parser.Flush()
+	t.Require().NoError(t.parser.ParseString("/ip route find where distance>1 and g").Error)
+	t.Require().Equal("ateway", t.parser.Continue().Merged)
+}
+
+func (t *CommandExpressionTestSuite) TestPanic() {
+	err := t.buildExpression("/ip route find; /ip route crash")
+	t.Require().ErrorIs(err, ErrPanic)
+	t.Require().Contains(err.Error(), "/ip route crash: panic: assignment to entry in nil map")
+
+	var execErr *ExecError
+	t.Require().ErrorAs(err, &execErr)
+	t.Require().Equal(Span{Offset: 16, Length: 15}, execErr.Span)
+}
+
+func (t *CommandExpressionTestSuite) TestSyntaxErrors() {
+	tests := []struct {
+		command string
+		offset  int
+		message string
+	}{
+		{"/ip firewall adx", 15, "unknown command `adx` under /ip firewall"},
+		{"/ip fire wall", 9, "unknown command `w` under /ip firewall"},
+		{"/ipx", 3, "unknown command `ipx`"},
+		{"/ip firewall add 1 2", 19, "expected flag name or `=`"},
+		{"/ip firewall add =1", 17, "expected flag name before `=`"},
+		{"/ip firewall add 1 area= 2", 24, "expected value after `=`"},
+		{"/ip firewall add 1 area=", 24, "expected value after `=`"},
+		{"/ip firewall add [/ip", 21, "unclosed `[`"},
+		{"/ip firewall add 1; {", 21, "unclosed `{`"},
+	}
+
+	for _, test := range tests {
+		err := t.buildExpression(test.command)
+
+		var syntaxErr *SyntaxError
+		t.Require().True(errors.As(err, &syntaxErr), test.command)
+		t.Require().ErrorIs(err, syntaxErr.Err)
+		t.Require().Equal(test.message, syntaxErr.Message, test.command)
+		t.Require().Equal(test.offset, syntaxErr.Offset, test.command)
+	}
+}
+
 func (t *CommandExpressionTestSuite) runTest(command string, expectedError error, expectedValues []*expectedValue) {
 	invoked := 0
 
@@ -279,12 +488,12 @@ func (t *CommandExpressionTestSuite) runTest(command string, expectedError error
 
 		t.Require().NoError(err)
 	} else {
-		if err != expectedError {
+		if !errors.Is(err, expectedError) {
 			fmt.Println(command)
 		}
 
 		t.Require().Error(err)
-		t.Require().Equal(expectedError, err)
+		t.Require().ErrorIs(err, expectedError)
 	}
 
 	t.Require().Equal(len(expectedValues), invoked)
@@ -299,7 +508,10 @@ func (t *CommandExpressionTestSuite) buildExpression(expr string) error {
 		return resp.Error
 	}
 
-	_, err := t.parser.Exec()
+	execResp, err := t.parser.Exec()
+	if err != nil {
+		return err
+	}
 
-	return err
+	return execResp.Error
 }
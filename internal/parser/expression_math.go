@@ -1,7 +1,8 @@
 package parser
 
 import (
-	"fmt"
+	"errors"
+	"strings"
 
 	"github.com/blkmlk/microshell/internal/models"
 )
@@ -14,9 +15,13 @@ const (
 	StateMathOperatorFinished
 	StateMathOperatorNotFinished
 	StateMathExpression
+	StateMathOperatorWord
+	StateMathQueryWord
 )
 
 type mathExpression struct {
+	Span
+
 	state             MathState
 	tree              *mathTree
 	lastExpression    Expression
@@ -24,9 +29,17 @@ type mathExpression struct {
 	openedBrackets    int
 	opened            int
 	lastOperator      Operator
+	operatorWord      string
 	prevRune          models.Rune
 	expressionBalance int
 	completed         bool
+
+	// a query takes its names as fields, see NewQueryExpression
+	query       bool
+	unbracketed bool
+	fields      []string
+	word        string
+	wordState   MathState
 }
 
 type MathState int
@@ -41,37 +54,53 @@ func (m *mathExpression) Type() ExpressionType {
 	return ExpressionTypeMath
 }
 
-func (m *mathExpression) Value(ctx SystemContext) Value {
+func (m *mathExpression) Value(ctx SystemContext) (Value, error) {
 	v, err := m.tree.Value(ctx)
 
 	if err != nil {
-		// TODO: handle it
-		//log.Fatal(err)
-		fmt.Println(err.Error())
+		var execErr *ExecError
+		if errors.As(err, &execErr) {
+			return nil, err
+		}
+
+		return nil, &ExecError{Span: m.Span, Err: err}
 	}
 
 	if v == nil {
 		v = NewNumberValue(0)
 	}
 
-	return v
+	return v, nil
 }
 
 func (m *mathExpression) Close(ctx SystemContext) *CloseResponse {
 	var resp CloseResponse
 
-	if m.state != StateMathExpression {
-		resp.Error = ErrNotFinished
-		return &resp
+	if m.state == StateMathQueryWord {
+		if r := m.endWord(' '); r.Err() != nil {
+			resp.Error = r.Err()
+			return &resp
+		}
 	}
 
-	m.tree.Add(m.lastExpression)
+	opened := m.opened
+	if m.unbracketed {
+		opened--
+	}
 
-	if m.opened > 0 {
+	if opened > 0 {
 		resp.UnclosedBrackets = '('
 		resp.Error = ErrNotFinished
+		return &resp
 	}
 
+	if m.state != StateMathExpression {
+		resp.Error = ErrNotFinished
+		return &resp
+	}
+
+	m.tree.Add(m.lastExpression)
+
 	return &resp
 }
 
@@ -80,6 +109,10 @@ func (m *mathExpression) Debug() string {
 }
 
 func (m *mathExpression) Complete(ctx SystemContext) *CompleteResponse {
+	if m.query {
+		return m.completeField()
+	}
+
 	return &CompleteResponse{}
 }
 
@@ -88,18 +121,41 @@ func (m *mathExpression) Add(ctx SystemContext, r models.Rune) *Response {
 
 	m.completed = false
 
+	// a word operator is followed by a space
+	if m.state == StateMathOperatorWord && !r.IsAlpha() && !r.Is(' ') {
+		return NewResponse().WithError(ErrWrongRune)
+	}
+
+	if m.state == StateMathQueryWord {
+		if isWordRune(r) {
+			m.word += r.String()
+			m.prevRune = r
+			return NewResponse().WithAction(ResponseGoNext).WithObject(ObjectValue)
+		}
+
+		if resp = m.endWord(r); resp.Err() != nil {
+			return resp
+		}
+	}
+
 	switch {
 	case r.Is(' '):
 		resp = m.handleSpace()
 	case r.IsNumber():
-		resp = m.handleAlpha(ctx)
-	case r.IsAlpha():
-		resp = m.handleAlpha(ctx)
+		resp = m.handleAlpha(ctx, r)
+	case r.IsAlpha() && m.query && m.valuePosition():
+		resp = m.startWord(r)
+	case r.IsAlpha() || r.Is(':'):
+		resp = m.handleAlpha(ctx, r)
 	case r.Is('"'):
 		resp = m.handleQuoteString(ctx)
 	case r.Is('!'):
-		resp = m.handleUnaryOperator()
-	case r.Is('.') || r.Is('+') || r.Is('-') || r.Is('/') || r.Is('*'):
+		resp = m.handleUnaryOperator(OperatorNot)
+	case r.Is('~'):
+		resp = m.handleTilde()
+	case r.Is('.') || r.Is('+') || r.Is('-') || r.Is('/') || r.Is('*') || r.Is('%'):
+		resp = m.handleOperator(r)
+	case r.Is('&') || r.Is('|') || r.Is('^'):
 		resp = m.handleOperator(r)
 	case r.Is('>') || r.Is('<') || r.Is('='):
 		resp = m.handleCompareOperator(r)
@@ -107,10 +163,13 @@ func (m *mathExpression) Add(ctx SystemContext, r models.Rune) *Response {
 		resp = m.handleOpenBracket()
 	case r.Is(')'):
 		resp = m.handleCloseBracket()
-	case r.Is('[') || r.Is(']'):
+	case r.Is('[') || r.Is(']') || r.Is('{') || r.Is('}'):
 		resp = m.handleCommandList(r)
 	case r.Is('$'):
 		resp = m.handleVariable()
+	case r.Is(';') && m.unbracketed && m.opened == 1 && m.openedBrackets == 0:
+		// the command goes on after a query
+		resp = NewResponse().WithAction(ResponseGoOut)
 	default:
 		return NewResponse().WithError(ErrWrongRune)
 	}
@@ -127,14 +186,38 @@ func (m *mathExpression) handleSpace() *Response {
 		m.state = StateMathOperatorFinished
 	}
 
+	if m.state == StateMathOperatorWord {
+		op, ok := wordOperators[m.operatorWord]
+		if !ok {
+			return resp.WithError(ErrWrongRune)
+		}
+
+		m.lastOperator = op
+		m.state = StateMathOperatorFinished
+	}
+
 	m.completed = true
 
 	return resp
 }
 
-func (m *mathExpression) handleAlpha(ctx SystemContext) *Response {
+func (m *mathExpression) handleAlpha(ctx SystemContext, r models.Rune) *Response {
 	var resp = NewResponse().WithAction(ResponseGoNext).WithObject(ObjectValue)
 
+	switch {
+	case m.state == StateMathExpression && r.IsAlpha() && m.prevRune.IsSpace():
+		m.state = StateMathOperatorWord
+		m.operatorWord = ""
+		m.tree.Add(m.lastExpression)
+		fallthrough
+	case m.state == StateMathOperatorWord:
+		m.operatorWord += r.String()
+		if !isWordOperatorPrefix(m.operatorWord) {
+			return resp.WithError(ErrWrongRune)
+		}
+		return resp.WithObject(ObjectOperator)
+	}
+
 	if m.state == StateMathExpression || m.state == StateMathOperatorNotAfterExpression {
 		return resp.WithError(ErrWrongRune)
 	}
@@ -174,7 +257,7 @@ func (m *mathExpression) handleQuoteString(ctx SystemContext) *Response {
 	return resp
 }
 
-func (m *mathExpression) handleUnaryOperator() *Response {
+func (m *mathExpression) handleUnaryOperator(op Operator) *Response {
 	var resp = NewResponse().WithAction(ResponseGoNext).WithObject(ObjectOperator)
 
 	switch m.state {
@@ -190,11 +273,28 @@ func (m *mathExpression) handleUnaryOperator() *Response {
 		m.tree.Add(m.lastOperator)
 	}
 
-	m.lastOperator = OperatorNot
+	m.lastOperator = op
 
 	return resp
 }
 
+// handleTilde matches a string against a regular expression after an
+// expression and inverts the bits otherwise.
+func (m *mathExpression) handleTilde() *Response {
+	switch m.state {
+	case StateMathExpression:
+		m.tree.Add(m.lastExpression)
+		m.lastOperator = OperatorMatch
+		m.state = StateMathOperatorFinished
+
+		return NewResponse().WithAction(ResponseGoNext).WithObject(ObjectOperator)
+	case StateMathOperatorNotAfterExpression:
+		return NewResponse().WithError(ErrWrongRune)
+	}
+
+	return m.handleUnaryOperator(OperatorBitNot)
+}
+
 func (m *mathExpression) handleOperator(r models.Rune) *Response {
 	var resp = NewResponse().WithAction(ResponseGoNext).WithObject(ObjectOperator)
 
@@ -206,9 +306,21 @@ func (m *mathExpression) handleOperator(r models.Rune) *Response {
 			return resp.WithError(ErrWrongRune)
 		}
 	case StateMathOperatorFinished:
-		if r.Is('+') || r.Is('-') {
-			m.tree.Add(m.lastOperator)
-		} else {
+		// && and || are written right after & and |
+		switch {
+		case r.Is('&') && m.prevRune.Is('&') && m.lastOperator == OperatorBitAnd:
+			m.lastOperator = OperatorAnd
+			return resp
+		case r.Is('|') && m.prevRune.Is('|') && m.lastOperator == OperatorBitOr:
+			m.lastOperator = OperatorOr
+			return resp
+		case r.Is('-'):
+			// a minus after an operator negates the operand, (3 - -2)
+			return m.handleUnaryOperator(OperatorNegate)
+		case r.Is('+'):
+			// and a plus leaves it as it is
+			return resp
+		default:
 			return resp.WithError(ErrWrongRune)
 		}
 	case StateMathExpression:
@@ -229,6 +341,14 @@ func (m *mathExpression) handleOperator(r models.Rune) *Response {
 		m.lastOperator = OperatorDivide
 	case '*':
 		m.lastOperator = OperatorMultiply
+	case '%':
+		m.lastOperator = OperatorModulo
+	case '&':
+		m.lastOperator = OperatorBitAnd
+	case '|':
+		m.lastOperator = OperatorBitOr
+	case '^':
+		m.lastOperator = OperatorBitXor
 	}
 
 	return resp
@@ -238,6 +358,12 @@ func (m *mathExpression) handleCompareOperator(r models.Rune) *Response {
 	var resp = NewResponse().WithAction(ResponseGoNext).WithObject(ObjectOperator)
 
 	switch m.state {
+	case StateMathOperatorFinished:
+		// -> picks an element of an array
+		if !r.Is('>') || !m.prevRune.Is('-') || m.lastOperator != OperatorMinus {
+			return resp.WithError(ErrWrongRune)
+		}
+		m.lastOperator = OperatorIndex
 	case StateMathOperatorNotAfterExpression:
 		if !r.Is('=') || m.prevRune.IsSpace() {
 			return resp.WithError(ErrWrongRune)
@@ -248,11 +374,15 @@ func (m *mathExpression) handleCompareOperator(r models.Rune) *Response {
 		if m.prevRune.IsSpace() {
 			return resp.WithError(ErrWrongRune)
 		}
-		switch m.lastOperator {
-		case OperatorLess:
+		switch {
+		case m.lastOperator == OperatorLess && r.Is('='):
 			m.lastOperator = OperatorLessOrEqual
-		case OperatorGreater:
+		case m.lastOperator == OperatorGreater && r.Is('='):
 			m.lastOperator = OperatorGreaterOrEqual
+		case m.lastOperator == OperatorLess && r.Is('<'):
+			m.lastOperator = OperatorShiftLeft
+		case m.lastOperator == OperatorGreater && r.Is('>'):
+			m.lastOperator = OperatorShiftRight
 		default:
 			return resp.WithError(ErrWrongRune)
 		}
@@ -260,11 +390,9 @@ func (m *mathExpression) handleCompareOperator(r models.Rune) *Response {
 	case StateMathExpression:
 		switch r {
 		case '>':
-			//	TODO: AddGlobal a bit shifting
 			m.lastOperator = OperatorGreater
 			m.state = StateMathOperatorNotFinished
 		case '<':
-			//	TODO: AddGlobal a bit shifting
 			m.lastOperator = OperatorLess
 			m.state = StateMathOperatorNotFinished
 		case '=':
@@ -302,7 +430,7 @@ func (m *mathExpression) handleOpenBracket() *Response {
 	m.state = StateMathExpression
 
 	m.openedQuoted = true
-	m.lastExpression = NewMathExpression()
+	m.lastExpression = m.newNested()
 	resp.WithAction(ResponseRepeat).WithExpression(m.lastExpression)
 
 	return resp
@@ -332,7 +460,7 @@ func (m *mathExpression) handleCommandList(r models.Rune) *Response {
 	var resp = NewResponse().WithAction(ResponseGoNext).WithObject(ObjectSquareBrackets)
 
 	switch {
-	case r.Is('['):
+	case r.Is('[') || r.Is('{'):
 		if m.state == StateMathExpression || m.state == StateMathOperatorNotAfterExpression {
 			return resp.WithError(ErrWrongRune)
 		}
@@ -343,10 +471,26 @@ func (m *mathExpression) handleCommandList(r models.Rune) *Response {
 
 		m.openedBrackets++
 		m.state = StateMathExpression
-		m.lastExpression = NewCommandList(false, false)
+
+		if r.Is('{') {
+			resp.WithObject(ObjectCurlyBrackets)
+			m.lastExpression = NewArrayExpression()
+		} else {
+			m.lastExpression = NewCommandList(false, false)
+		}
+
 		resp.WithAction(ResponseRepeat).WithExpression(m.lastExpression)
-	case r.Is(']'):
+	case r.Is(']') || r.Is('}'):
+		if r.Is('}') {
+			resp.WithObject(ObjectCurlyBrackets)
+		}
+
 		if m.openedBrackets <= 0 {
+			// an unpaired bracket closes the command list of a query
+			if m.unbracketed {
+				return resp.WithAction(ResponseGoOut)
+			}
+
 			return resp.WithError(ErrWrongRune)
 		}
 
@@ -373,3 +517,13 @@ func (m *mathExpression) handleVariable() *Response {
 
 	return resp
 }
+
+func isWordOperatorPrefix(word string) bool {
+	for w := range wordOperators {
+		if strings.HasPrefix(w, word) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/blkmlk/microshell/internal/logger"
+	"github.com/blkmlk/microshell/internal/terminal"
 
 	"github.com/sarulabs/di/v2"
 
@@ -27,9 +28,11 @@ func (t *MathExpressionTestSuite) SetupTest() {
 	err = builder.Add(
 		Definition,
 		DefinitionContext,
+		DefinitionClock,
 		DefinitionScope,
 		DefinitionCommandTree,
 		logger.Definition,
+		terminal.DefinitionBuffer,
 	)
 	t.Require().NoError(err)
 
@@ -141,10 +144,10 @@ func (t *MathExpressionTestSuite) TestMathExpression() {
 	t.Require().NoError(err)
 
 	_, err = t.makeExpression(`(1 + !5)`)
-	t.Require().NoError(err)
+	t.Require().ErrorIs(err, ErrWrongType)
 
 	_, err = t.makeExpression(`(1 / !5)`)
-	t.Require().NoError(err)
+	t.Require().ErrorIs(err, ErrWrongType)
 
 	v, err = t.makeExpression(`(5 / 2)`)
 	t.Require().NoError(err)
@@ -195,6 +198,125 @@ func (t *MathExpressionTestSuite) TestMathExpression() {
 	t.Require().Equal(true, v.Bool())
 }
 
+func (t *MathExpressionTestSuite) TestTypedValues() {
+	t.scope.SetCommandRoot(NewCommandTree())
+	t.scope.SetCommandTree(NewCommandTree())
+
+	for expr, expected := range map[string]string{
+		`(10.0.0.1)`:                  "10.0.0.1",
+		`(10.0.0.1 + 1)`:              "10.0.0.2",
+		`(10.0.0.255 + 1)`:            "10.0.1.0",
+		`(10.0.1.0 - 1)`:              "10.0.0.255",
+		`(10.0.0.10 - 10.0.0.1)`:      "9",
+		`(1 + 192.168.0.1)`:           "192.168.0.2",
+		`(10.0.0.2 > 10.0.0.1)`:       "true",
+		`(10.0.0.1 = 10.0.0.1)`:       "true",
+		`(10.0.0.1 in 10.0.0.0/8)`:    "true",
+		`(11.0.0.1 in 10.0.0.0/8)`:    "false",
+		`(10.1.0.0/16 in 10.0.0.0/8)`: "true",
+		`(10.0.0.0/8 in 10.1.0.0/16)`: "false",
+		`(10.0.0.5/24)`:               "10.0.0.5/24",
+		`(2001:db8::1 + 1)`:           "2001:db8::2",
+		`(fe80::1 in fe80::/10)`:      "true",
+		`(::1 = ::1)`:                 "true",
+		`(1h30m)`:                     "01:30:00",
+		`(1h - 10m)`:                  "00:50:00",
+		`(00:05:00 + 30s)`:            "00:05:30",
+		`(1d2h)`:                      "1d02:00:00",
+		`(1w + 1d)`:                   "1w1d00:00:00",
+		`(500ms * 3)`:                 "00:00:01.500",
+		`(1h / 4)`:                    "00:15:00",
+		`(-10m)`:                      "-00:10:00",
+		`(1h > 59m)`:                  "true",
+		`(1h / 30m)`:                  "2",
+		`(10.0.0.1 . "/32")`:          "10.0.0.1/32",
+	} {
+		v, err := t.makeExpression(expr)
+		t.Require().NoError(err, expr)
+		t.Require().Equal(expected, v.String(), expr)
+	}
+
+	for _, expr := range []string{
+		`(10.0.0.256)`,
+		`(1x)`,
+		`(1 inn 2)`,
+		`(1 in(2))`,
+		`(fx)`,
+	} {
+		_, err := t.makeExpression(expr)
+		t.Require().Error(err, expr)
+	}
+}
+
+func (t *MathExpressionTestSuite) TestOperators() {
+	t.scope.SetCommandRoot(NewCommandTree())
+	t.scope.SetCommandTree(NewCommandTree())
+
+	for expr, expected := range map[string]string{
+		`(7 % 3)`:                        "1",
+		`(2 + 7 % 4 * 2)`:                "8",
+		`(true && false)`:                "false",
+		`(true || false)`:                "true",
+		`(1 < 2 && 2 < 3)`:               "true",
+		`(false && true || true)`:        "true",
+		`(true || true && false)`:        "true",
+		`(!true || true)`:                "true",
+		`(false && (1 / 0 = 0))`:         "false",
+		`(true || (1 / 0 = 0))`:          "true",
+		`(12 & 10)`:                      "8",
+		`(12 | 3)`:                       "15",
+		`(12 ^ 10)`:                      "6",
+		`(1 | 2 ^ 3 & 6)`:                "1",
+		`(~0)`:                           "-1",
+		`(5 & ~1)`:                       "4",
+		`(1 << 4)`:                       "16",
+		`(256 >> 4)`:                     "16",
+		`(1 << 2 + 1)`:                   "8",
+		`(1 << 2 = 4)`:                   "true",
+		`("router1" ~ "^router[0-9]+$")`: "true",
+		`("switch" ~ "^router")`:         "false",
+		`(192.168.1.77 & 255.255.255.0)`: "192.168.1.0",
+		`(10.0.0.0 | 0.0.0.255)`:         "10.0.0.255",
+		`(~255.255.255.0)`:               "0.0.0.255",
+		`(3 - -2)`:                       "5",
+		`(2 * -3)`:                       "-6",
+		`(1 + -5)`:                       "-4",
+		`(1 + +5)`:                       "6",
+		`(2 - - 3)`:                      "5",
+		`(2 * -(1 + 1))`:                 "-4",
+		`(10 / -2 - 1)`:                  "-6",
+	} {
+		v, err := t.makeExpression(expr)
+		t.Require().NoError(err, expr)
+		t.Require().Equal(expected, v.String(), expr)
+	}
+
+	for _, expr := range []string{
+		`(&& true)`,
+		`(1 & & 2)`,
+		`(1 &&& 2)`,
+		`(1 < < 2)`,
+		`(1 !~ 2)`,
+		`(1 - - - 2)`,
+		`(2 * -"a")`,
+		`(% 2)`,
+	} {
+		_, err := t.makeExpression(expr)
+		t.Require().Error(err, expr)
+	}
+
+	resp := t.parser.ParseString(`(1 << 2 && ~3)`)
+	t.Require().NoError(resp.Error)
+
+	var operators []int
+	for _, obj := range resp.Objects {
+		if obj.Object == ObjectOperator {
+			operators = append(operators, obj.Length)
+		}
+	}
+	t.Require().Equal([]int{2, 2, 1}, operators)
+}
+
 func (t *MathExpressionTestSuite) makeExpression(expr string) (Value, error) {
 	t.parser.Flush()
 
@@ -0,0 +1,211 @@
+package parser
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/blkmlk/microshell/internal/models"
+)
+
+const ExpressionTypeField = "expression-field"
+
+// Properties are the fields of the item a query is evaluated for.
+type Properties interface {
+	// Property returns the value of the field, false if there is no such
+	// field.
+	Property(name string) (Value, bool)
+}
+
+type propertiesKey struct{}
+
+// WithProperties returns a scope in which the names of a query are the
+// properties of the item.
+func WithProperties(ctx SystemContext, properties Properties) SystemContext {
+	scope := ctx.Copy()
+	return scope.WithContext(context.WithValue(scope.Ctx(), propertiesKey{}, properties))
+}
+
+// NewQueryExpression returns the expression of a where flag. It runs up to
+// the end of the command and takes the names in it as fields, and, or and
+// not as the logical operators.
+func NewQueryExpression(fields []string) Expression {
+	return &mathExpression{
+		tree:        NewMathTree(),
+		query:       true,
+		unbracketed: true,
+		opened:      1,
+		fields:      fields,
+	}
+}
+
+func (m *mathExpression) newNested() Expression {
+	if m.query {
+		return &mathExpression{
+			tree:   NewMathTree(),
+			query:  true,
+			fields: m.fields,
+		}
+	}
+
+	return NewMathExpression()
+}
+
+// valuePosition reports whether a value is expected next.
+func (m *mathExpression) valuePosition() bool {
+	switch m.state {
+	case StateMathExpression, StateMathOperatorNotAfterExpression, StateMathOperatorWord, StateMathQueryWord:
+		return false
+	}
+
+	return true
+}
+
+func (m *mathExpression) startWord(r models.Rune) *Response {
+	m.wordState = m.state
+	m.state = StateMathQueryWord
+	m.word = r.String()
+
+	return NewResponse().WithAction(ResponseGoNext).WithObject(ObjectValue)
+}
+
+// endWord turns the word into a field or the not operator once the rune
+// after it has come.
+func (m *mathExpression) endWord(r models.Rune) *Response {
+	m.state = m.wordState
+
+	if m.word == "not" {
+		if !r.IsSpace() && !r.Is('(') {
+			return NewResponse().WithError(ErrWrongRune)
+		}
+
+		return m.handleUnaryOperator(OperatorNot)
+	}
+
+	if m.fieldPosition() && !m.isField(m.word) {
+		return NewResponse().WithError(syntaxError("unknown field `%s`", m.word))
+	}
+
+	if m.state != StateMathNone {
+		m.tree.Add(m.lastOperator)
+	}
+
+	m.state = StateMathExpression
+	m.lastExpression = &fieldExpression{name: m.word}
+
+	return NewResponse().WithAction(ResponseGoNext)
+}
+
+// fieldPosition reports whether the word is an operand of and, or and not,
+// where it can only be a field. A word compared to a field can be a value,
+// e.g. tcp in protocol=tcp.
+func (m *mathExpression) fieldPosition() bool {
+	if m.state == StateMathNone {
+		return true
+	}
+
+	switch m.lastOperator {
+	case OperatorAnd, OperatorOr, OperatorNot:
+		return true
+	}
+
+	return false
+}
+
+// isField reports whether the query has the field, any name is a field of a
+// query without them.
+func (m *mathExpression) isField(name string) bool {
+	if len(m.fields) == 0 {
+		return true
+	}
+
+	for _, field := range m.fields {
+		if field == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// completeField offers the fields starting with the word being written.
+func (m *mathExpression) completeField() *CompleteResponse {
+	var prefix string
+
+	switch {
+	case m.state == StateMathQueryWord:
+		prefix = m.word
+	case !m.valuePosition():
+		return &CompleteResponse{}
+	}
+
+	var names []string
+	for _, name := range m.fields {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	var resp CompleteResponse
+	for _, name := range names {
+		resp.Options = append(resp.Options, &CompleteOption{Level: LevelTypeFlag, Option: name})
+	}
+
+	if len(names) > 0 {
+		merged := names[0]
+		for _, name := range names[1:] {
+			for !strings.HasPrefix(name, merged) {
+				merged = merged[:len(merged)-1]
+			}
+		}
+
+		resp.Merged = merged[len(prefix):]
+	}
+
+	return &resp
+}
+
+func isWordRune(r models.Rune) bool {
+	return r.IsAlpha() || r.IsNumber() || r.Is('-')
+}
+
+// fieldExpression is a name in a query. It is the property of the item or
+// the word itself if the item has no such property, e.g. yes, no or tcp.
+type fieldExpression struct {
+	name string
+}
+
+func (f *fieldExpression) Type() ExpressionType {
+	return ExpressionTypeField
+}
+
+func (f *fieldExpression) Add(ctx SystemContext, r models.Rune) *Response {
+	return NewResponse().WithError(ErrWrongRune)
+}
+
+func (f *fieldExpression) Complete(ctx SystemContext) *CompleteResponse {
+	return nil
+}
+
+func (f *fieldExpression) Close(ctx SystemContext) *CloseResponse {
+	return &CloseResponse{}
+}
+
+func (f *fieldExpression) Value(ctx SystemContext) (Value, error) {
+	if properties, ok := ctx.Value(propertiesKey{}).(Properties); ok {
+		if value, ok := properties.Property(f.name); ok {
+			return value, nil
+		}
+	}
+
+	switch f.name {
+	case "yes":
+		return NewBoolValue(true), nil
+	case "no":
+		return NewBoolValue(false), nil
+	}
+
+	return ParseValue(f.name), nil
+}
@@ -15,6 +15,13 @@ var (
 type stdExpression struct {
 	strictMode bool
 	quotes     int
+	// literal is set for an unquoted address, network or duration
+	literal bool
+	// kind is set by the constructors and, for the parsed values, from the
+	// quotes and the text once the value is closed
+	kind ValueKind
+	// typed is the address, network or duration an unquoted word holds
+	typed Value
 
 	boolValueIdx int
 	boolValue    []models.Rune
@@ -30,22 +37,23 @@ func (s *stdExpression) Type() ExpressionType {
 	return ExpressionTypeStd
 }
 
-func NewStringValue(v string) Value {
-	exp := new(stdExpression)
+func newValue(kind ValueKind, v string) *stdExpression {
+	exp := &stdExpression{kind: kind}
 	exp.value.WriteString(v)
 
 	return exp
 }
 
+func NewStringValue(v string) Value {
+	return newValue(KindString, v)
+}
+
 func NewNumberValue(v int) Value {
-	return NewStringValue(strconv.Itoa(v))
+	return newValue(KindNumber, strconv.Itoa(v))
 }
 
 func NewBoolValue(value bool) Value {
-	if value {
-		return NewStringValue("true")
-	}
-	return NewStringValue("false")
+	return newValue(KindBool, strconv.FormatBool(value))
 }
 
 func (s *stdExpression) Complete(ctx SystemContext) *CompleteResponse {
@@ -56,7 +64,7 @@ func (s *stdExpression) Add(ctx SystemContext, r models.Rune) *Response {
 	var resp = NewResponse().WithAction(ResponseGoNext).WithObject(ObjectValue)
 
 	switch {
-	case r.IsAlpha():
+	case r.IsAlpha() || r.IsUnicodeLetter():
 		if s.quotes >= 2 {
 			return resp.WithObject(ObjectValue)
 		}
@@ -66,7 +74,8 @@ func (s *stdExpression) Add(ctx SystemContext, r models.Rune) *Response {
 			return resp.WithObject(ObjectQuotedString)
 		}
 
-		if !s.strictMode {
+		if !s.strictMode || s.literal || (s.value.Len() > 0 && s.boolValue == nil) {
+			s.literal = s.literal || s.strictMode
 			s.value.WriteRune(rune(r))
 			return resp.WithObject(ObjectValue)
 		}
@@ -76,6 +85,10 @@ func (s *stdExpression) Add(ctx SystemContext, r models.Rune) *Response {
 				s.boolValue = boolValueTrue
 			} else if r.Is('f') {
 				s.boolValue = boolValueFalse
+			} else if isHex(r) {
+				s.literal = true
+				s.value.WriteRune(rune(r))
+				return resp.WithObject(ObjectValue)
 			} else {
 				return resp.WithError(ErrWrongRune)
 			}
@@ -85,6 +98,11 @@ func (s *stdExpression) Add(ctx SystemContext, r models.Rune) *Response {
 		}
 
 		if s.boolValueIdx >= len(s.boolValue) || s.boolValue[s.boolValueIdx] != r {
+			// fa, fe80 etc. start an IPv6 address
+			if isHex(r) && s.toLiteral() {
+				s.value.WriteRune(rune(r))
+				return resp.WithObject(ObjectValue)
+			}
 			return resp.WithError(ErrWrongRune)
 		}
 
@@ -99,6 +117,9 @@ func (s *stdExpression) Add(ctx SystemContext, r models.Rune) *Response {
 		return resp.WithObject(ObjectValue)
 	case r.IsNumber():
 		resp.WithObject(ObjectValue)
+		if s.strictMode && s.boolValueIdx > 0 && !s.toLiteral() {
+			return resp.WithError(ErrWrongRune)
+		}
 		s.value.WriteRune(rune(r))
 	case r.IsSpace():
 		resp.WithObject(ObjectSpace)
@@ -116,6 +137,10 @@ func (s *stdExpression) Add(ctx SystemContext, r models.Rune) *Response {
 			return resp.WithError(ErrWrongRune)
 		}
 
+		if !s.validLiteral() {
+			return resp.WithError(ErrWrongRune)
+		}
+
 		return resp.WithAction(ResponseGoOut)
 	case r.Is('"'):
 		resp.WithObject(ObjectQuotedSymbol)
@@ -132,15 +157,105 @@ func (s *stdExpression) Add(ctx SystemContext, r models.Rune) *Response {
 		if s.quotes > 2 {
 			return resp.WithError(ErrWrongRune)
 		}
+	case s.quotes == 1:
+		// a quoted string holds any character
+		s.value.WriteRune(rune(r))
+		return resp.WithObject(ObjectQuotedString)
+	case (r.Is('.') || r.Is(':') || r.Is('/')) && s.quotes == 0 && s.literalRune(r):
+		s.value.WriteRune(rune(r))
+	case (r.Is('-') || r.Is('_')) && !s.strictMode && s.quotes == 0:
+		// a word may hold dashes and underscores, e.g. -5, dst-nat or a
+		// file name
+		s.value.WriteRune(rune(r))
+	case r.Is('*') && !s.strictMode && s.quotes == 0 && s.value.Len() == 0:
+		// an internal id, e.g. *1A
+		s.value.WriteRune(rune(r))
 	default:
+		if !s.validLiteral() {
+			return resp.WithError(ErrWrongRune)
+		}
 		return resp.WithAction(ResponseGoOut)
 	}
 
 	return resp
 }
 
-func (s *stdExpression) Value(ctx SystemContext) Value {
-	return s
+func (s *stdExpression) Value(ctx SystemContext) (Value, error) {
+	if s.literal {
+		if typed := literal(s); typed != Value(s) {
+			return typed, nil
+		}
+	}
+
+	return s, nil
+}
+
+// literalRune reports whether the separator continues an unquoted address,
+// network or duration. In the strict mode a dot only follows a number or an
+// IPv6 address and a slash only follows an address, so (1 . 2) and (4/2) keep
+// their operators.
+func (s *stdExpression) literalRune(r models.Rune) bool {
+	if !s.strictMode {
+		// a file path starts with a slash
+		return s.value.Len() > 0 || r.Is('/')
+	}
+
+	// ::1 starts with a colon
+	if s.value.Len() == 0 && !s.toLiteral() && !r.Is(':') {
+		return false
+	}
+
+	v := s.value.String()
+
+	switch {
+	case r.Is('/'):
+		if !strings.ContainsAny(v, ".:") {
+			return false
+		}
+	case r.Is('.'):
+		if strings.Trim(v, "0123456789.") != "" && !strings.Contains(v, ":") {
+			return false
+		}
+	}
+
+	s.literal = true
+	return true
+}
+
+// toLiteral turns the beginning of a bool into the beginning of an IPv6
+// address.
+func (s *stdExpression) toLiteral() bool {
+	if s.boolValueIdx == 0 || s.boolValueIdx == len(s.boolValue) {
+		return false
+	}
+
+	for _, c := range s.boolValue[:s.boolValueIdx] {
+		if !isHex(c) {
+			return false
+		}
+	}
+
+	for _, c := range s.boolValue[:s.boolValueIdx] {
+		s.value.WriteRune(rune(c))
+	}
+
+	s.boolValue, s.boolValueIdx = nil, 0
+	s.literal = true
+
+	return true
+}
+
+func (s *stdExpression) validLiteral() bool {
+	if !s.literal || !s.strictMode {
+		return true
+	}
+
+	_, ok := parseLiteral(s.String())
+	return ok
+}
+
+func isHex(r models.Rune) bool {
+	return r.IsNumber() || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
 }
 
 func (s *stdExpression) Close(ctx SystemContext) *CloseResponse {
@@ -152,14 +267,59 @@ func (s *stdExpression) Close(ctx SystemContext) *CloseResponse {
 		return &resp
 	}
 
-	if s.boolValueIdx != len(s.boolValue) {
+	if s.boolValueIdx != len(s.boolValue) || !s.validLiteral() {
 		resp.Error = ErrWrongRune
 		return &resp
 	}
 
+	s.settle()
+
 	return &resp
 }
 
+// settle fixes the kind of the parsed value, a quoted value stays a string
+// whatever it holds.
+func (s *stdExpression) settle() {
+	if s.kind == "" {
+		s.kind, s.typed = s.parseKind()
+	}
+}
+
+// parseKind works the kind out of the quotes and the text, an unquoted
+// address, network or duration is returned typed.
+func (s *stdExpression) parseKind() (ValueKind, Value) {
+	v := s.value.String()
+
+	switch {
+	case s.quotes > 0:
+		return KindString, nil
+	case v == "":
+		return KindNothing, nil
+	case v == "true" || v == "false":
+		return KindBool, nil
+	}
+
+	if _, err := strconv.Atoi(v); err == nil {
+		return KindNumber, nil
+	}
+
+	if typed, ok := parseLiteral(v); ok {
+		return typed.Kind(), typed
+	}
+
+	return KindString, nil
+}
+
+func (s *stdExpression) Kind() ValueKind {
+	if s.kind != "" {
+		return s.kind
+	}
+
+	// the value is still being parsed
+	kind, _ := s.parseKind()
+	return kind
+}
+
 func (s *stdExpression) Bool() bool {
 	return s.value.String() == "true"
 }
@@ -168,6 +328,12 @@ func (s *stdExpression) Number() int {
 	if s.value.Len() == 0 {
 		return 0
 	}
+
+	// an address or a duration written as a word
+	if typed := literal(s); typed != Value(s) {
+		return typed.Number()
+	}
+
 	v, _ := strconv.Atoi(s.value.String())
 	return v
 }
@@ -177,49 +343,48 @@ func (s *stdExpression) String() string {
 }
 
 func (s *stdExpression) IsBool() bool {
-	v := s.value.String()
-	return v == "true" || v == "false"
+	return s.Kind() == KindBool
 }
 
 func (s *stdExpression) IsString() bool {
-	return true
+	return s.Kind() == KindString
 }
 
-func (s stdExpression) IsNumber() bool {
-	if s.value.Len() == 0 {
-		return true
-	}
-
-	_, err := strconv.Atoi(s.value.String())
-	return err == nil
+func (s *stdExpression) IsNumber() bool {
+	return s.Kind() == KindNumber
 }
 
+// Equal compares the values of the same kind, "5" doesn't equal 5.
 func (s *stdExpression) Equal(v Value) bool {
+	if s.Kind() != v.Kind() {
+		return false
+	}
+
+	if s.IsNumber() {
+		return s.Number() == v.Number()
+	}
+
 	return s.String() == v.String()
 }
 
 func (s *stdExpression) Less(v Value) bool {
 	switch {
-	case s.IsBool():
+	case s.Kind() != v.Kind() || s.IsBool():
 		return false
 	case s.IsNumber():
 		return s.Number() < v.Number()
-	case s.IsString():
-		return strings.Compare(s.String(), v.String()) == -1
 	}
 
-	return false
+	return strings.Compare(s.String(), v.String()) == -1
 }
 
-func (s stdExpression) Greater(v Value) bool {
+func (s *stdExpression) Greater(v Value) bool {
 	switch {
-	case s.IsBool():
+	case s.Kind() != v.Kind() || s.IsBool():
 		return false
 	case s.IsNumber():
 		return s.Number() > v.Number()
-	case s.IsString():
-		return strings.Compare(s.String(), v.String()) == 1
 	}
 
-	return false
+	return strings.Compare(s.String(), v.String()) == 1
 }
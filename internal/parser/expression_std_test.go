@@ -7,6 +7,7 @@ import (
 	"github.com/blkmlk/microshell/internal/models"
 
 	"github.com/blkmlk/microshell/internal/logger"
+	"github.com/blkmlk/microshell/internal/terminal"
 
 	"github.com/sarulabs/di/v2"
 	"github.com/stretchr/testify/suite"
@@ -30,6 +31,7 @@ func (t *stdTestSuite) SetupTest() {
 	err = builder.Add(
 		Definition,
 		DefinitionContext,
+		DefinitionClock,
 		DefinitionScope,
 		di.Def{
 			Name: DefinitionNameCommandTree,
@@ -38,6 +40,7 @@ func (t *stdTestSuite) SetupTest() {
 			},
 		},
 		logger.Definition,
+		terminal.DefinitionBuffer,
 	)
 	t.Require().NoError(err)
 
@@ -49,7 +52,9 @@ func (t *stdTestSuite) SetupTest() {
 
 func (t *stdTestSuite) TestStrictBool() {
 	checkValue := func(ctx SystemContext, exp Expression, value interface{}) {
-		t.Require().Equal(value, exp.Value(ctx).Bool())
+		v, err := exp.Value(ctx)
+		t.Require().NoError(err)
+		t.Require().Equal(value, v.Bool())
 	}
 
 	t.Require().NoError(t.testCase(true, `false`, false, checkValue))
@@ -70,7 +75,9 @@ func (t *stdTestSuite) TestStrictBool() {
 
 func (t *stdTestSuite) TestString() {
 	checkValue := func(ctx SystemContext, exp Expression, value interface{}) {
-		t.Require().Equal(value, exp.Value(ctx).String())
+		v, err := exp.Value(ctx)
+		t.Require().NoError(err)
+		t.Require().Equal(value, v.String())
 	}
 
 	t.Require().NoError(t.testCase(false, `"hello"`, "hello", checkValue))
@@ -85,7 +92,9 @@ func (t *stdTestSuite) TestString() {
 
 func (t *stdTestSuite) TestNumber() {
 	checkValue := func(ctx SystemContext, exp Expression, value interface{}) {
-		t.Require().Equal(value, exp.Value(ctx).Number())
+		v, err := exp.Value(ctx)
+		t.Require().NoError(err)
+		t.Require().Equal(value, v.Number())
 	}
 
 	t.Require().NoError(t.testCase(false, `"hello"`, 0, checkValue))
@@ -96,6 +105,66 @@ func (t *stdTestSuite) TestNumber() {
 	t.Require().Error(t.testCase(false, ` 123`, nil, nil))
 }
 
+func (t *stdTestSuite) TestKind() {
+	checkValue := func(ctx SystemContext, exp Expression, value interface{}) {
+		v, err := exp.Value(ctx)
+		t.Require().NoError(err)
+		t.Require().Equal(value, v.Kind())
+	}
+
+	t.Require().NoError(t.testCase(false, `"5"`, KindString, checkValue))
+	t.Require().NoError(t.testCase(false, `""`, KindString, checkValue))
+	t.Require().NoError(t.testCase(false, `5`, KindNumber, checkValue))
+	t.Require().NoError(t.testCase(false, `true`, KindBool, checkValue))
+	t.Require().NoError(t.testCase(false, `"true"`, KindString, checkValue))
+	t.Require().NoError(t.testCase(false, `hello`, KindString, checkValue))
+	t.Require().NoError(t.testCase(false, `-5`, KindNumber, checkValue))
+	t.Require().NoError(t.testCase(false, `dst-nat`, KindString, checkValue))
+	t.Require().NoError(t.testCase(false, `10.0.0.1`, KindIP, checkValue))
+	t.Require().NoError(t.testCase(false, `10.0.0.0/8`, KindIPPrefix, checkValue))
+	t.Require().NoError(t.testCase(false, `fe80::1`, KindIP6, checkValue))
+	t.Require().NoError(t.testCase(false, `fe80::/10`, KindIP6Prefix, checkValue))
+	t.Require().NoError(t.testCase(false, `1h30m`, KindTime, checkValue))
+	t.Require().NoError(t.testCase(true, `00:05:00`, KindTime, checkValue))
+
+	t.Require().Equal(KindNothing, NullValue.Kind())
+	t.Require().Equal(KindNil, NilValue.Kind())
+	t.Require().Equal(KindString, NewStringValue("5").Kind())
+	t.Require().Equal(KindNumber, NewNumberValue(5).Kind())
+	t.Require().Equal(KindBool, NewBoolValue(false).Kind())
+
+	t.Require().False(NewStringValue("5").Equal(NewNumberValue(5)))
+	t.Require().False(NewStringValue("").IsNumber())
+	t.Require().True(NewNumberValue(5).Equal(NewNumberValue(5)))
+}
+
+func (t *stdTestSuite) TestKindOnClose() {
+	parse := func(s string) *stdExpression {
+		exp := NewStdExpression(false).(*stdExpression)
+		for _, c := range s {
+			t.Require().NoError(exp.Add(t.ctx, models.Rune(c)).Err(), s)
+		}
+		t.Require().NoError(exp.Close(t.ctx).Error, s)
+
+		return exp
+	}
+
+	// the kind is set once the value is closed
+	quoted := parse(`"10.0.0.1"`)
+	t.Require().Equal(KindString, quoted.kind)
+	t.Require().Nil(quoted.typed)
+	t.Require().Same(quoted, literal(quoted))
+
+	bare := parse(`10.0.0.1`)
+	t.Require().Equal(KindIP, bare.kind)
+	t.Require().Equal(KindIP, literal(bare).Kind())
+	t.Require().False(bare.Equal(quoted))
+
+	t.Require().Equal(KindNumber, parse(`-5`).kind)
+	t.Require().Equal(KindString, parse(`"5"`).kind)
+	t.Require().Equal(KindTime, ParseValue("1h").Kind())
+}
+
 func (t *stdTestSuite) testCase(strictMode bool, s string, expectedValue interface{}, checkValue func(SystemContext, Expression, interface{})) error {
 	exp := NewStdExpression(strictMode)
 
@@ -68,7 +68,7 @@ func (v *variableExpression) Add(ctx SystemContext, r models.Rune) *Response {
 	switch {
 	case r.Is('$'):
 		resp = v.handleVarSign(ctx)
-	case r.IsNumber() || r.IsAlpha():
+	case r.IsNumber() || r.IsAlpha() || r.IsUnicodeLetter():
 		resp = v.handleAlpha(ctx, r)
 	case r.Is('='):
 		resp = v.handleEqual()
@@ -211,23 +211,23 @@ func (v *variableExpression) handleMath(r models.Rune) *Response {
 	return resp
 }
 
-func (v *variableExpression) Value(ctx SystemContext) Value {
+func (v *variableExpression) Value(ctx SystemContext) (Value, error) {
 	if v.iterator == nil {
-		return NullValue
+		return NullValue, nil
 	}
 
 	payload := ctx.GetVariablePayload(v.name)
 
 	if payload == nil {
-		return NullValue
+		return NullValue, nil
 	}
 
 	if value, ok := payload.(Value); ok {
-		return value
+		return value, nil
 	}
 
 	if _, ok := payload.(Valuer); !ok {
-		return NullValue
+		return NullValue, nil
 	}
 
 	valuer := payload.(Valuer)
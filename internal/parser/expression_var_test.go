@@ -6,6 +6,7 @@ import (
 	"github.com/blkmlk/microshell/internal/mocks"
 
 	"github.com/blkmlk/microshell/internal/logger"
+	"github.com/blkmlk/microshell/internal/terminal"
 
 	"github.com/sarulabs/di/v2"
 
@@ -43,6 +44,7 @@ func (t *variableTestSuite) SetupTest() {
 	err = builder.Add(
 		Definition,
 		DefinitionContext,
+		DefinitionClock,
 		DefinitionScope,
 		logger.Definition,
 		di.Def{
@@ -66,6 +68,7 @@ func (t *variableTestSuite) SetupTest() {
 				}}, nil
 			},
 		},
+		terminal.DefinitionBuffer,
 	)
 	t.Require().NoError(err)
 
@@ -160,9 +163,11 @@ func (t *variableTestSuite) buildExpression(s string, expectedError error, te *t
 			ctx := args.Get(0).(SystemContext)
 			invoked = true
 			for name, value := range te.Vars {
-				t.Require().Equal(value, ctx.GetVariable(name).String())
+				v, err := ctx.GetVariable(name)
+				t.Require().NoError(err)
+				t.Require().Equal(value, v.String())
 			}
-		}).Return(NewStringValue(te.ReturnValue)).Once()
+		}).Return(NewStringValue(te.ReturnValue), nil).Once()
 	}
 
 	t.parser.Flush()
@@ -172,7 +177,7 @@ func (t *variableTestSuite) buildExpression(s string, expectedError error, te *t
 	if parseResp.Error != nil {
 		if expectedError != nil {
 			t.Require().Error(parseResp.Error)
-			t.Require().Equal(expectedError, parseResp.Error)
+			t.Require().ErrorIs(parseResp.Error, expectedError)
 		} else {
 			t.Fail(parseResp.Error.Error())
 		}
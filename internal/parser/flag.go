@@ -1,9 +1,16 @@
 package parser
 
 type Flag struct {
-	Name      string
-	Mandatory bool
-	Number    uint
+	Name        string
+	Description string
+	Mandatory   bool
+	Number      uint
+	// Secret keeps the commands using the flag out of the history.
+	Secret bool
+	// Query takes the rest of the command as a where expression, the names
+	// in it are completed from Fields.
+	Query  bool
+	Fields []string
 	ValueType
 	expression Expression
 }
@@ -16,14 +23,18 @@ func (f *Flag) Expression() Expression {
 	return f.expression
 }
 
-func (f *Flag) Value(ctx SystemContext) Value {
+func (f *Flag) Value(ctx SystemContext) (Value, error) {
 	return f.expression.Value(ctx)
 }
 
 func (f *Flag) Copy() *Flag {
 	copied := new(Flag)
 	copied.Name = f.Name
+	copied.Description = f.Description
 	copied.Mandatory = f.Mandatory
+	copied.Secret = f.Secret
+	copied.Query = f.Query
+	copied.Fields = f.Fields
 	copied.ValueType = f.ValueType
 
 	return copied
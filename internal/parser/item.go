@@ -33,7 +33,8 @@ func addItemToTree(tree *CommandTree, items map[string]*Item) {
 			Payload: item.Payload,
 		}
 
-		if len(item.Children) > 0 {
+		// a command without flags still gets a tree of its own
+		if len(item.Children) > 0 || item.Level == LevelTypeCommand {
 			p.NextTree = NewCommandTree()
 			addItemToTree(p.NextTree, item.Children)
 		}
@@ -0,0 +1,127 @@
+package parser
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blkmlk/microshell/internal/terminal"
+)
+
+// Job is a command list running in the background.
+type Job struct {
+	ID      int
+	Owner   string
+	Started time.Time
+	Source  string
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Done is closed when the job has ended.
+func (j *Job) Done() <-chan struct{} {
+	return j.done
+}
+
+// Jobs is the table of the running jobs, it is shared by all the scopes of a
+// root scope.
+type Jobs struct {
+	ctx    context.Context
+	clock  Clock
+	mu     sync.Mutex
+	lastID int
+	jobs   map[int]*Job
+}
+
+// NewJobs returns an empty table, the jobs are cancelled with ctx.
+func NewJobs(ctx context.Context, clock Clock) *Jobs {
+	return &Jobs{
+		ctx:   ctx,
+		clock: clock,
+		jobs:  make(map[int]*Job),
+	}
+}
+
+// Start runs the function in its own goroutine and scope. The output of the
+// job is kept in a buffer of its own and goes to the log when the job ends.
+func (j *Jobs) Start(ctx SystemContext, source string, run func(ctx SystemContext) error) *Job {
+	jobCtx, cancel := context.WithCancel(j.ctx)
+	buffer := terminal.NewBuffer()
+
+	j.mu.Lock()
+	j.lastID++
+	job := &Job{
+		ID:      j.lastID,
+		Owner:   ctx.User(),
+		Started: j.clock.Now(),
+		Source:  source,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	j.jobs[job.ID] = job
+	j.mu.Unlock()
+
+	scope := ctx.New().WithContext(jobCtx).WithBuffer(buffer)
+
+	go func() {
+		defer close(job.done)
+		defer j.Remove(job.ID)
+
+		err := run(scope)
+		logOutput(scope, job, buffer, err)
+	}()
+
+	return job
+}
+
+// List returns the running jobs ordered by their id.
+func (j *Jobs) List() []*Job {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	jobs := make([]*Job, 0, len(j.jobs))
+	for _, job := range j.jobs {
+		jobs = append(jobs, job)
+	}
+
+	sort.Slice(jobs, func(a, b int) bool {
+		return jobs[a].ID < jobs[b].ID
+	})
+
+	return jobs
+}
+
+// Remove cancels the job and drops it from the table, it returns false if
+// there is no such job.
+func (j *Jobs) Remove(id int) bool {
+	j.mu.Lock()
+	job, ok := j.jobs[id]
+	delete(j.jobs, id)
+	j.mu.Unlock()
+
+	if ok {
+		job.cancel()
+	}
+
+	return ok
+}
+
+// logOutput writes the output of the job and its failure to the log, a
+// removed job isn't a failure.
+func logOutput(ctx SystemContext, job *Job, buffer terminal.Buffer, err error) {
+	for out, exists := buffer.Pop(); exists; out, exists = buffer.Pop() {
+		var text strings.Builder
+		for _, w := range out.Words(0, 0) {
+			text.WriteString(w.Text())
+		}
+
+		ctx.Logger().WriteMessages("job", job.ID, ":", text.String())
+	}
+
+	if err != nil && ctx.Err() == nil {
+		ctx.Logger().WriteMessages("job", job.ID, "failed:", err.Error())
+	}
+}
@@ -0,0 +1,79 @@
+package parser
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/blkmlk/microshell/internal/logger"
+	"github.com/blkmlk/microshell/internal/terminal"
+	"github.com/sarulabs/di/v2"
+	"github.com/stretchr/testify/suite"
+)
+
+type jobsTestSuite struct {
+	suite.Suite
+	ctx SystemContext
+}
+
+func TestJobs(t *testing.T) {
+	suite.Run(t, new(jobsTestSuite))
+}
+
+func (t *jobsTestSuite) SetupTest() {
+	builder, err := di.NewBuilder()
+	t.Require().NoError(err)
+
+	err = builder.Add(
+		DefinitionContext,
+		di.Def{
+			Name: DefinitionNameClock,
+			Build: func(ctn di.Container) (interface{}, error) {
+				return newFakeClock(time.Date(2021, 8, 20, 10, 0, 0, 0, time.UTC)), nil
+			},
+		},
+		DefinitionScope,
+		DefinitionCommandTree,
+		logger.Definition,
+		terminal.DefinitionBuffer,
+	)
+	t.Require().NoError(err)
+
+	t.ctx = builder.Build().Get(DefinitionNameRootScope).(SystemContext).WithUser("admin")
+}
+
+func (t *jobsTestSuite) TestJobs() {
+	jobs := t.ctx.Jobs()
+
+	started := make(chan SystemContext, 1)
+	long := jobs.Start(t.ctx, "{ :delay 1h }", func(ctx SystemContext) error {
+		started <- ctx
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	short := jobs.Start(t.ctx, "{}", func(ctx SystemContext) error {
+		return nil
+	})
+	<-short.Done()
+
+	scope := <-started
+	t.Require().NotSame(t.ctx.Buffer(), scope.Buffer())
+	t.Require().Equal("admin", scope.User())
+
+	list := jobs.List()
+	t.Require().Len(list, 1)
+	t.Require().Equal(1, list[0].ID)
+	t.Require().Equal("admin", list[0].Owner)
+	t.Require().Equal("{ :delay 1h }", list[0].Source)
+	t.Require().Equal(2021, list[0].Started.Year())
+
+	t.Require().True(jobs.Remove(long.ID))
+	<-long.Done()
+	t.Require().ErrorIs(scope.Err(), context.Canceled)
+	t.Require().Empty(jobs.List())
+	t.Require().False(jobs.Remove(long.ID))
+
+	// the jobs are shared by the scopes of the root
+	t.Require().Same(jobs, t.ctx.New().Copy().Jobs())
+}
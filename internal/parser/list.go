@@ -6,6 +6,15 @@ import (
 
 type List struct {
 	Commands []*Command
+	// Menus describe the paths of the commands, a path without a menu is
+	// listed without a description
+	Menus []*Menu
+}
+
+// Menu is the path the commands are grouped under, e.g. "/ip firewall".
+type Menu struct {
+	Path        []string
+	Description string
 }
 
 func (l *List) Items() (map[string]*Item, error) {
@@ -16,14 +25,14 @@ func (l *List) Items() (map[string]*Item, error) {
 		var item *Item
 		var ok bool
 
-		for _, path := range c.Path {
+		for i, path := range c.Path {
 			item, ok = items[path]
 
 			if !ok {
 				item = new(Item)
 				item.Level = LevelTypePath
 				item.Children = make(map[string]*Item)
-				item.Payload = &Payload{}
+				item.Payload = &Menu{Path: append([]string{}, c.Path[:i+1]...)}
 
 				items[path] = item
 			}
@@ -40,6 +49,7 @@ func (l *List) Items() (map[string]*Item, error) {
 			item.Payload = c
 
 			c.unnamedFlags = make(map[uint]*Flag)
+			c.MandatoryFlags = nil
 
 			items[c.Name] = item
 		}
@@ -54,10 +64,6 @@ func (l *List) Items() (map[string]*Item, error) {
 			}
 
 			if flag.Number > 0 {
-				if !flag.Mandatory {
-					return nil, fmt.Errorf("flag %v is unnamed and not mandatory", flagName)
-				}
-
 				if _, ok := c.unnamedFlags[flag.Number]; ok {
 					return nil, fmt.Errorf("number %v is already set", flag.Number)
 				}
@@ -98,7 +104,41 @@ func (l *List) Items() (map[string]*Item, error) {
 				return nil, fmt.Errorf("unnamed flags are ordered wrong")
 			}
 		}
+
+		// the optional unnamed flags may only be left out at the end
+		for i := 1; i <= len(c.unnamedFlags); i++ {
+			flag := c.unnamedFlags[uint(i)]
+			if flag.Mandatory {
+				if i > 1 && !c.unnamedFlags[uint(i-1)].Mandatory {
+					return nil, fmt.Errorf("flag %v is unnamed and follows an optional one", flag.Name)
+				}
+			} else if i == 1 {
+				return nil, fmt.Errorf("flag %v is unnamed and not mandatory", flag.Name)
+			}
+		}
+	}
+
+	for _, m := range l.Menus {
+		if item := findPath(result, m.Path); item != nil {
+			item.Payload = m
+		}
 	}
 
 	return result, nil
 }
+
+// findPath returns the path item at path, nil if no command is under it.
+func findPath(items map[string]*Item, path []string) *Item {
+	var item *Item
+
+	for _, name := range path {
+		var ok bool
+		if item, ok = items[name]; !ok || item.Level != LevelTypePath {
+			return nil
+		}
+
+		items = item.Children
+	}
+
+	return item
+}
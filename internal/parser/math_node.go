@@ -1,6 +1,10 @@
 package parser
 
-import "errors"
+import (
+	"errors"
+	"regexp"
+	"time"
+)
 
 type mathNode struct {
 	Item  interface{}
@@ -11,7 +15,7 @@ type mathNode struct {
 func (m *mathNode) Value(ctx SystemContext) (Value, error) {
 	switch i := m.Item.(type) {
 	case Valuer:
-		return i.Value(ctx), nil
+		return i.Value(ctx)
 	case Operator:
 		var left Value
 
@@ -24,12 +28,24 @@ func (m *mathNode) Value(ctx SystemContext) (Value, error) {
 			}
 		}
 
+		if i == OperatorAnd || i == OperatorOr {
+			return m.processLogical(ctx, i, left)
+		}
+
+		if m.Right == nil {
+			return nil, ErrNoOperand
+		}
+
 		right, err := m.Right.Value(ctx)
 
 		if err != nil {
 			return nil, err
 		}
 
+		if right == nil {
+			return nil, ErrNoOperand
+		}
+
 		return m.processOperator(i, left, right)
 	case nil:
 		return nil, nil
@@ -39,10 +55,38 @@ func (m *mathNode) Value(ctx SystemContext) (Value, error) {
 }
 
 func (m *mathNode) processOperator(op Operator, left, right Value) (Value, error) {
+	if op == OperatorIndex {
+		return index(left, right)
+	}
+
 	if op == OperatorConcatenate {
 		return NewStringValue(left.String() + right.String()), nil
 	}
 
+	if left != nil {
+		left = literal(left)
+	}
+	right = literal(right)
+
+	if op == OperatorIn {
+		prefix, ok := right.(*prefixValue)
+		if !ok {
+			return nil, ErrWrongType
+		}
+		return NewBoolValue(prefix.Contains(left)), nil
+	}
+
+	if op == OperatorMatch {
+		if left.Kind() != KindString || right.Kind() != KindString {
+			return nil, ErrWrongType
+		}
+		matched, err := regexp.MatchString(right.String(), left.String())
+		if err != nil {
+			return nil, err
+		}
+		return NewBoolValue(matched), nil
+	}
+
 	if op&OperatorTypeMask == OperatorTypeCompare {
 		switch op {
 		case OperatorNotEqual:
@@ -50,22 +94,22 @@ func (m *mathNode) processOperator(op Operator, left, right Value) (Value, error
 		case OperatorEqual:
 			return NewBoolValue(left.Equal(right)), nil
 		case OperatorGreater:
-			if left.IsBool() || right.IsBool() {
+			if !ordered(left, right) {
 				return nil, ErrWrongType
 			}
 			return NewBoolValue(left.Greater(right)), nil
 		case OperatorGreaterOrEqual:
-			if left.IsBool() || right.IsBool() {
+			if !ordered(left, right) {
 				return nil, ErrWrongType
 			}
 			return NewBoolValue(left.Greater(right) || left.Equal(right)), nil
 		case OperatorLess:
-			if left.IsBool() || right.IsBool() {
+			if !ordered(left, right) {
 				return nil, ErrWrongType
 			}
 			return NewBoolValue(left.Less(right)), nil
 		case OperatorLessOrEqual:
-			if left.IsBool() || right.IsBool() {
+			if !ordered(left, right) {
 				return nil, ErrWrongType
 			}
 			return NewBoolValue(left.Less(right) || left.Equal(right)), nil
@@ -79,14 +123,35 @@ func (m *mathNode) processOperator(op Operator, left, right Value) (Value, error
 				return nil, ErrWrongType
 			}
 			return NewBoolValue(!right.Bool()), nil
+		case OperatorBitNot:
+			if ip, ok := right.(*ipValue); ok {
+				return ip.not(), nil
+			}
+			if right.Kind() != KindNumber {
+				return nil, ErrWrongType
+			}
+			return NewNumberValue(^right.Number()), nil
+		case OperatorNegate:
+			if !numeric(right) {
+				return nil, ErrWrongType
+			}
+			return NewNumberValue(-right.Number()), nil
 		}
 
 		return nil, ErrWrongOperator
 	}
 
-	// Type Addition or Multiply
+	if op.IsType(OperatorTypeBitAnd) || op.IsType(OperatorTypeBitOr) || op.IsType(OperatorTypeBitXor) {
+		return bitwise(op, left, right)
+	}
+
+	// Type Addition, Multiply or Shift
+
+	if v, ok, err := typedArithmetic(op, left, right); ok {
+		return v, err
+	}
 
-	if !((left == nil || left.IsNumber()) && right.IsNumber()) {
+	if !((left == nil || numeric(left)) && numeric(right)) {
 		return nil, ErrWrongType
 	}
 
@@ -115,6 +180,28 @@ func (m *mathNode) processOperator(op Operator, left, right Value) (Value, error
 			return nil, errors.New("left expression is nil")
 		}
 		return NewNumberValue(left.Number() / right.Number()), nil
+	case OperatorModulo:
+		if right.Number() == 0 {
+			return nil, errors.New("division by zero")
+		}
+
+		if left == nil {
+			return nil, errors.New("left expression is nil")
+		}
+		return NewNumberValue(left.Number() % right.Number()), nil
+	case OperatorShiftLeft, OperatorShiftRight:
+		if left == nil {
+			return nil, errors.New("left expression is nil")
+		}
+
+		if right.Number() < 0 {
+			return nil, errors.New("negative shift")
+		}
+
+		if op == OperatorShiftLeft {
+			return NewNumberValue(left.Number() << uint(right.Number())), nil
+		}
+		return NewNumberValue(left.Number() >> uint(right.Number())), nil
 	default:
 		return nil, errors.New("wrong Operator")
 	}
@@ -146,7 +233,8 @@ func (m *mathNode) Add(item interface{}) *mathNode {
 		}
 
 		if o, ok := m.Item.(Operator); ok {
-			if i.LessOrEqualThan(o) && o != OperatorNot {
+			// a unary operator takes the next one as its operand
+			if i.LessOrEqualThan(o) && !(o.IsType(OperatorTypeUnary) && m.Right == nil) {
 				node := new(mathNode)
 				node.Item = i
 				node.Left = m
@@ -166,3 +254,185 @@ func (m *mathNode) Add(item interface{}) *mathNode {
 
 	return m
 }
+
+// processLogical evaluates the right operand only if the left one doesn't
+// decide the result already.
+func (m *mathNode) processLogical(ctx SystemContext, op Operator, left Value) (Value, error) {
+	if left == nil || !left.IsBool() {
+		return nil, ErrWrongType
+	}
+
+	if (op == OperatorAnd) != left.Bool() {
+		return left, nil
+	}
+
+	right, err := m.Right.Value(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !right.IsBool() {
+		return nil, ErrWrongType
+	}
+
+	return right, nil
+}
+
+// bitwise applies &, | and ^ to numbers or to addresses of the same family.
+func bitwise(op Operator, left, right Value) (Value, error) {
+	if l, ok := left.(*ipValue); ok {
+		r, ok := right.(*ipValue)
+		if !ok || l.Is6() != r.Is6() {
+			return nil, ErrWrongType
+		}
+		return l.bitwise(op, r), nil
+	}
+
+	if left == nil || left.Kind() != KindNumber || right.Kind() != KindNumber {
+		return nil, ErrWrongType
+	}
+
+	switch op {
+	case OperatorBitAnd:
+		return NewNumberValue(left.Number() & right.Number()), nil
+	case OperatorBitOr:
+		return NewNumberValue(left.Number() | right.Number()), nil
+	case OperatorBitXor:
+		return NewNumberValue(left.Number() ^ right.Number()), nil
+	}
+
+	return nil, ErrWrongOperator
+}
+
+// ordered reports whether the values can be compared by the order, they are
+// numbers, strings, addresses or durations of the same kind.
+func ordered(left, right Value) bool {
+	if left.Kind() != right.Kind() {
+		return false
+	}
+
+	switch left.Kind() {
+	case KindNumber, KindString, KindIP, KindIP6, KindTime:
+		return true
+	}
+
+	return false
+}
+
+// numeric reports whether the value takes part in the arithmetic, nothing
+// counts as 0.
+func numeric(v Value) bool {
+	return v.Kind() == KindNumber || v.Kind() == KindNothing
+}
+
+// index returns the element of the array by its key or position, nothing if
+// there is none.
+func index(left, right Value) (Value, error) {
+	array, ok := left.(Array)
+	if !ok {
+		return nil, ErrWrongType
+	}
+
+	if v, ok := array.Get(right.String()); ok {
+		return v, nil
+	}
+
+	if right.IsNumber() && right.String() != "" {
+		if v := array.Index(right.Number()); v != nil {
+			return v, nil
+		}
+	}
+
+	return NullValue, nil
+}
+
+// typedArithmetic moves the addresses by numbers and adds, subtracts, scales
+// the durations. It reports false if neither operand is an address or a
+// duration.
+func typedArithmetic(op Operator, left, right Value) (Value, bool, error) {
+	switch l := left.(type) {
+	case *ipValue:
+		switch r := right.(type) {
+		case *ipValue:
+			if op != OperatorMinus {
+				return nil, true, ErrWrongType
+			}
+			n, ok := l.sub(r)
+			if !ok {
+				return nil, true, ErrWrongType
+			}
+			return NewNumberValue(n), true, nil
+		}
+
+		if !right.IsNumber() {
+			return nil, true, ErrWrongType
+		}
+
+		switch op {
+		case OperatorPlus:
+			return l.add(right.Number()), true, nil
+		case OperatorMinus:
+			return l.add(-right.Number()), true, nil
+		}
+
+		return nil, true, ErrWrongType
+	case *timeValue:
+		switch r := right.(type) {
+		case *timeValue:
+			switch op {
+			case OperatorPlus:
+				return NewTimeValue(l.d + r.d), true, nil
+			case OperatorMinus:
+				return NewTimeValue(l.d - r.d), true, nil
+			case OperatorDivide:
+				if r.d == 0 {
+					return nil, true, errors.New("division by zero")
+				}
+				return NewNumberValue(int(l.d / r.d)), true, nil
+			}
+
+			return nil, true, ErrWrongType
+		}
+
+		if !right.IsNumber() {
+			return nil, true, ErrWrongType
+		}
+
+		switch op {
+		case OperatorMultiply:
+			return NewTimeValue(l.d * time.Duration(right.Number())), true, nil
+		case OperatorDivide:
+			if right.Number() == 0 {
+				return nil, true, errors.New("division by zero")
+			}
+			return NewTimeValue(l.d / time.Duration(right.Number())), true, nil
+		}
+
+		return nil, true, ErrWrongType
+	case *prefixValue:
+		return nil, true, ErrWrongType
+	}
+
+	switch r := right.(type) {
+	case *ipValue:
+		if left == nil || !left.IsNumber() || op != OperatorPlus {
+			return nil, true, ErrWrongType
+		}
+		return r.add(left.Number()), true, nil
+	case *timeValue:
+		switch {
+		case left == nil && op == OperatorPlus:
+			return r, true, nil
+		case left == nil && op == OperatorMinus:
+			return NewTimeValue(-r.d), true, nil
+		case left != nil && left.IsNumber() && op == OperatorMultiply:
+			return NewTimeValue(r.d * time.Duration(left.Number())), true, nil
+		}
+
+		return nil, true, ErrWrongType
+	case *prefixValue:
+		return nil, true, ErrWrongType
+	}
+
+	return nil, false, nil
+}
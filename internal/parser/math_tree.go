@@ -7,37 +7,67 @@ import (
 var (
 	ErrWrongType       = errors.New("wrong type")
 	ErrWrongOperator   = errors.New("wrong operator")
+	ErrNoOperand       = errors.New("missing operand")
 	ErrNoMandatoryFlag = errors.New("no mandatory flag")
 )
 
+// The type bits of an operator order the precedence, the lowest bits tell
+// the operators of the same type apart.
 const (
-	OperatorTypeCompare     Operator = 1 << 3
-	OperatorTypeConcatenate Operator = 1 << 4
-	OperatorTypeAddition    Operator = 1 << 5
-	OperatorTypeMultiply    Operator = 1 << 6
-	OperatorTypeUnary       Operator = 1 << 7
-	OperatorTypeMask        Operator = 0xf8 // 1111 1000
+	OperatorTypeOr          Operator = 1 << 3
+	OperatorTypeAnd         Operator = 1 << 4
+	OperatorTypeBitOr       Operator = 1 << 5
+	OperatorTypeBitXor      Operator = 1 << 6
+	OperatorTypeBitAnd      Operator = 1 << 7
+	OperatorTypeCompare     Operator = 1 << 8
+	OperatorTypeShift       Operator = 1 << 9
+	OperatorTypeConcatenate Operator = 1 << 10
+	OperatorTypeAddition    Operator = 1 << 11
+	OperatorTypeMultiply    Operator = 1 << 12
+	OperatorTypeUnary       Operator = 1 << 13
+	OperatorTypeIndex       Operator = 1 << 14
+	OperatorTypeMask        Operator = 0x7ff8 // 111 1111 1111 1000
 )
 
 const (
+	OperatorOr             = OperatorTypeOr
+	OperatorAnd            = OperatorTypeAnd
+	OperatorBitOr          = OperatorTypeBitOr
+	OperatorBitXor         = OperatorTypeBitXor
+	OperatorBitAnd         = OperatorTypeBitAnd
 	OperatorEqual          = OperatorTypeCompare
 	OperatorNotEqual       = OperatorTypeCompare + 1
 	OperatorGreater        = OperatorTypeCompare + 2
 	OperatorGreaterOrEqual = OperatorTypeCompare + 3
 	OperatorLess           = OperatorTypeCompare + 4
 	OperatorLessOrEqual    = OperatorTypeCompare + 5
+	OperatorIn             = OperatorTypeCompare + 6
+	OperatorMatch          = OperatorTypeCompare + 7
+	OperatorShiftLeft      = OperatorTypeShift
+	OperatorShiftRight     = OperatorTypeShift + 1
 	OperatorConcatenate    = OperatorTypeConcatenate
 	OperatorPlus           = OperatorTypeAddition
 	OperatorMinus          = OperatorTypeAddition + 1
 	OperatorMultiply       = OperatorTypeMultiply
 	OperatorDivide         = OperatorTypeMultiply + 1
+	OperatorModulo         = OperatorTypeMultiply + 2
 	OperatorNot            = OperatorTypeUnary
+	OperatorBitNot         = OperatorTypeUnary + 1
+	OperatorNegate         = OperatorTypeUnary + 2
+	OperatorIndex          = OperatorTypeIndex
 )
 
 type (
 	Operator int
 )
 
+// wordOperators are written with letters and separated by spaces.
+var wordOperators = map[string]Operator{
+	"in":  OperatorIn,
+	"and": OperatorAnd,
+	"or":  OperatorOr,
+}
+
 func (o Operator) LessOrEqualThan(op Operator) bool {
 	return o&OperatorTypeMask <= op&OperatorTypeMask
 }
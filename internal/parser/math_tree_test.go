@@ -7,6 +7,7 @@ import (
 
 	"github.com/blkmlk/microshell/internal/logger"
 	"github.com/blkmlk/microshell/internal/models"
+	"github.com/blkmlk/microshell/internal/terminal"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -26,9 +27,11 @@ func (t *MathTreeExpressionTestSuite) SetupTest() {
 	err = builder.Add(
 		Definition,
 		DefinitionContext,
+		DefinitionClock,
 		DefinitionScope,
 		DefinitionCommandTree,
 		logger.Definition,
+		terminal.DefinitionBuffer,
 	)
 	t.Require().NoError(err)
 
@@ -117,6 +120,80 @@ func (t *MathTreeExpressionTestSuite) TestMathTree_Add() {
 	t.Require().True(v.Bool())
 }
 
+func (t *MathTreeExpressionTestSuite) TestTypedValues() {
+	for _, items := range [][]interface{}{
+		{"10.0.0.1", OperatorPlus, "10.0.0.1"},
+		{"10.0.0.1", OperatorMultiply, "2"},
+		{"10.0.0.1", OperatorMinus, "2001:db8::1"},
+		{"10.0.0.1", OperatorIn, "10.0.0.1"},
+		{"10.0.0.0/8", OperatorPlus, "1"},
+		{"1h", OperatorPlus, "1"},
+		{"1h", OperatorMultiply, "1h"},
+		{`"10.0.0.1"`, OperatorPlus, "1"},
+	} {
+		_, err := t.buildTree(items).Value(t.ctx)
+		t.Require().ErrorIs(err, ErrWrongType, items)
+	}
+
+	v, err := t.buildTree([]interface{}{"2001:db8::ffff", OperatorMinus, "2001:db8::1"}).Value(t.ctx)
+	t.Require().NoError(err)
+	t.Require().Equal(65534, v.Number())
+
+	v, err = t.buildTree([]interface{}{"255.255.255.255", OperatorPlus, "1"}).Value(t.ctx)
+	t.Require().NoError(err)
+	t.Require().Equal("0.0.0.0", v.String())
+}
+
+func (t *MathTreeExpressionTestSuite) TestOperators() {
+	for _, items := range [][]interface{}{
+		{"1", OperatorAnd, "true"},
+		{"true", OperatorAnd, "1"},
+		{"1", OperatorMatch, `"1"`},
+		{`"a"`, OperatorBitAnd, "1"},
+		{"10.0.0.1", OperatorBitOr, "::1"},
+		{OperatorBitNot, "true"},
+		{"1h", OperatorModulo, "2"},
+	} {
+		_, err := t.buildTree(items).Value(t.ctx)
+		t.Require().ErrorIs(err, ErrWrongType, items)
+	}
+
+	for _, items := range [][]interface{}{
+		{"5", OperatorModulo, "0"},
+		{"1", OperatorShiftLeft, OperatorMinus, "1"},
+		{`"a"`, OperatorMatch, `"("`},
+	} {
+		_, err := t.buildTree(items).Value(t.ctx)
+		t.Require().Error(err, items)
+	}
+
+	// an operator without its operand fails rather than panics
+	for _, items := range [][]interface{}{
+		{"3", OperatorMinus, OperatorMinus, "2"},
+		{"2", OperatorMultiply},
+	} {
+		_, err := t.buildTree(items).Value(t.ctx)
+		t.Require().ErrorIs(err, ErrNoOperand, items)
+	}
+
+	v, err := t.buildTree([]interface{}{"3", OperatorMinus, OperatorNegate, "2"}).Value(t.ctx)
+	t.Require().NoError(err)
+	t.Require().Equal(5, v.Number())
+
+	// the right operand isn't evaluated once the left one decides
+	v, err = t.buildTree([]interface{}{"false", OperatorAnd, "1", OperatorDivide, "0"}).Value(t.ctx)
+	t.Require().NoError(err)
+	t.Require().False(v.Bool())
+
+	v, err = t.buildTree([]interface{}{"true", OperatorOr, "1", OperatorDivide, "0"}).Value(t.ctx)
+	t.Require().NoError(err)
+	t.Require().True(v.Bool())
+
+	v, err = t.buildTree([]interface{}{OperatorNot, "false", OperatorAnd, "false"}).Value(t.ctx)
+	t.Require().NoError(err)
+	t.Require().False(v.Bool())
+}
+
 func (t *MathTreeExpressionTestSuite) buildTree(items []interface{}) *mathTree {
 	tree := NewMathTree()
 
@@ -75,7 +75,7 @@ func (_m *MockExpression) Type() ExpressionType {
 }
 
 // Value provides a mock function with given fields: ctx
-func (_m *MockExpression) Value(ctx SystemContext) Value {
+func (_m *MockExpression) Value(ctx SystemContext) (Value, error) {
 	ret := _m.Called(ctx)
 
 	var r0 Value
@@ -87,5 +87,12 @@ func (_m *MockExpression) Value(ctx SystemContext) Value {
 		}
 	}
 
-	return r0
+	var r1 error
+	if rf, ok := ret.Get(1).(func(SystemContext) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
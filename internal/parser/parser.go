@@ -3,6 +3,7 @@ package parser
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/blkmlk/microshell/internal/logger"
 
@@ -23,6 +24,8 @@ type ParseRuneResponse struct {
 type ParseStringResponse struct {
 	Objects []*ParsedObject
 	Error   error
+	// Secret is set when the string uses a secret flag.
+	Secret bool
 }
 
 type ExecResponse struct {
@@ -32,11 +35,17 @@ type ExecResponse struct {
 }
 
 type parser struct {
-	logger          logger.Logger
-	rootCtx         SystemContext
+	logger  logger.Logger
+	rootCtx SystemContext
+	// shared runs every string in rootCtx itself rather than in a new scope
+	shared          bool
 	currentCtx      SystemContext
 	currentCancel   context.CancelFunc
 	expressionStack *ExpressionStack
+	secret          bool
+	// position is the offset of the next rune in text
+	position int
+	text     []rune
 }
 
 func newParser(ctn di.Container) Parser {
@@ -48,12 +57,32 @@ func newParser(ctn di.Container) Parser {
 	return parser
 }
 
+// NewScriptParser returns a parser running every string in scope itself, so
+// the locals of a statement stay for the next ones as in a script.
+func NewScriptParser(scope SystemContext) Parser {
+	p := &parser{
+		logger:  scope.Logger(),
+		rootCtx: scope,
+		shared:  true,
+	}
+	p.Flush()
+
+	return p
+}
+
 func (p *parser) Flush() {
 	ctx, cancel := context.WithCancel(p.rootCtx.Ctx())
-	p.currentCtx = p.rootCtx.New().WithContext(ctx)
+	if p.shared {
+		p.currentCtx = p.rootCtx.Copy().WithContext(ctx)
+	} else {
+		p.currentCtx = p.rootCtx.New().WithContext(ctx)
+	}
 	p.currentCancel = cancel
 	p.expressionStack = newExpressionStack()
 	p.expressionStack.Push(p.currentCtx, NewCommandList(true, false))
+	p.secret = false
+	p.position = 0
+	p.text = p.text[:0]
 }
 
 func (p *parser) IsFlushed() bool {
@@ -61,6 +90,16 @@ func (p *parser) IsFlushed() bool {
 }
 
 func (p *parser) Add(r models.Rune) (*ParseRuneResponse, error) {
+	p.text = append(p.text, rune(r))
+
+	defer func() {
+		p.position++
+	}()
+
+	return p.add(r)
+}
+
+func (p *parser) add(r models.Rune) (*ParseRuneResponse, error) {
 	ctx, exp := p.expressionStack.Pop()
 
 	if exp == nil {
@@ -73,9 +112,17 @@ func (p *parser) Add(r models.Rune) (*ParseRuneResponse, error) {
 		return nil, resp.Err()
 	}
 
+	if resp.Secret() {
+		p.secret = true
+	}
+
 	if resp.Expression() != nil && resp.Expression() != exp && resp.Action() != ResponseGoOut {
 		p.expressionStack.Push(ctx, exp)
 		exp = resp.Expression()
+
+		if s, ok := exp.(spanned); ok {
+			s.setStart(p.position)
+		}
 	}
 
 	switch resp.ContextType() {
@@ -90,19 +137,28 @@ func (p *parser) Add(r models.Rune) (*ParseRuneResponse, error) {
 		p.expressionStack.Push(ctx, exp)
 	case ResponseRepeat:
 		p.expressionStack.Push(ctx, exp)
-		return p.Add(r)
+
+		for _, replayed := range resp.Replay() {
+			if _, err := p.add(replayed); err != nil {
+				return nil, err
+			}
+		}
+
+		return p.add(r)
 	case ResponseGoOut:
 		if p.expressionStack.Size() == 0 {
 			return nil, errors.New("can't go out")
 		}
 
+		p.endSpan(exp)
+
 		closeResp := exp.Close(ctx)
 
 		if resp != nil && closeResp.Error != nil {
 			return nil, closeResp.Error
 		}
 
-		return p.Add(r)
+		return p.add(r)
 	}
 
 	return &ParseRuneResponse{
@@ -125,10 +181,11 @@ func (p *parser) ParseString(s string) *ParseStringResponse {
 		r := models.Rune(c)
 
 		if err == nil {
+			offset := p.position
 			resp, inErr := p.Add(r)
 
 			if inErr != nil {
-				err = inErr
+				err = newSyntaxError(inErr, r, offset)
 
 				if r.IsSpace() {
 					obj.Object = ObjectError
@@ -172,13 +229,31 @@ func (p *parser) ParseString(s string) *ParseStringResponse {
 	}
 
 	response.Error = err
+	response.Secret = p.secret
 
 	return &response
 }
 
 func (p *parser) Exec() (*ExecResponse, error) {
+	ctx, exp, resp, err := p.close()
+	if err != nil || resp.Error != nil {
+		return resp, err
+	}
+
+	value, err := exp.Value(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Value = value
+
+	return resp, nil
+}
+
+// close closes the expressions left on the stack and returns the root one.
+func (p *parser) close() (SystemContext, Expression, *ExecResponse, error) {
 	if p.expressionStack.Size() == 0 {
-		return nil, errors.New("no expression")
+		return nil, nil, nil, errors.New("no expression")
 	}
 
 	var (
@@ -190,18 +265,47 @@ func (p *parser) Exec() (*ExecResponse, error) {
 	for p.expressionStack.Size() != 0 {
 		ctx, exp = p.expressionStack.Pop()
 
+		p.endSpan(exp)
+
 		closeResp := exp.Close(ctx)
 
 		if closeResp.Error != nil {
-			resp.Error = closeResp.Error
+			resp.Error = newCloseError(closeResp, p.position)
 			resp.UnclosedBrackets = closeResp.UnclosedBrackets
-			return &resp, nil
+			return nil, nil, &resp, nil
 		}
 	}
 
-	resp.Value = exp.Value(ctx)
+	return ctx, exp, &resp, nil
+}
+
+// Parse returns the expression of the whole source, it is evaluated later in
+// any scope. The commands are looked up from the scope given.
+func Parse(ctx SystemContext, source string) (Expression, error) {
+	p := &parser{
+		logger:  ctx.Logger(),
+		rootCtx: ctx,
+	}
+	p.Flush()
+
+	if resp := p.ParseString(source); resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	_, exp, resp, err := p.close()
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
 
-	return &resp, nil
+	return exp, nil
+}
+
+func (p *parser) Cancel() {
+	p.currentCancel()
 }
 
 func (p *parser) Continue() *CompleteResponse {
@@ -210,3 +314,53 @@ func (p *parser) Continue() *CompleteResponse {
 
 	return exp.Complete(ctx)
 }
+
+// sourced is an expression that keeps the text it has been parsed from.
+type sourced interface {
+	setSource(text []rune)
+}
+
+func (p *parser) endSpan(exp Expression) {
+	if s, ok := exp.(spanned); ok {
+		s.setEnd(p.position)
+	}
+
+	if s, ok := exp.(sourced); ok {
+		s.setSource(p.text)
+	}
+}
+
+// newSyntaxError returns the failure at the rune, a wrong rune is described
+// by the rune itself if the expression hasn't told more.
+func newSyntaxError(err error, r models.Rune, offset int) *SyntaxError {
+	var syntaxErr *SyntaxError
+
+	if !errors.As(err, &syntaxErr) {
+		syntaxErr = &SyntaxError{Message: fmt.Sprintf("unexpected `%c`", r), Err: err}
+
+		if r.IsSpace() {
+			syntaxErr.Message = "unexpected space"
+		}
+	}
+
+	syntaxErr.Offset = offset
+
+	return syntaxErr
+}
+
+// newCloseError returns the failure at the end of the string.
+func newCloseError(resp *CloseResponse, offset int) *SyntaxError {
+	var syntaxErr *SyntaxError
+
+	switch {
+	case resp.UnclosedBrackets != 0:
+		syntaxErr = &SyntaxError{Message: fmt.Sprintf("unclosed `%c`", resp.UnclosedBrackets), Err: resp.Error}
+	case errors.As(resp.Error, &syntaxErr):
+	default:
+		syntaxErr = &SyntaxError{Message: "unexpected end of command", Err: resp.Error}
+	}
+
+	syntaxErr.Offset = offset
+
+	return syntaxErr
+}
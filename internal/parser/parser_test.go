@@ -9,6 +9,7 @@ import (
 
 	"github.com/blkmlk/microshell/internal/logger"
 	"github.com/blkmlk/microshell/internal/models"
+	"github.com/blkmlk/microshell/internal/terminal"
 
 	"github.com/sarulabs/di/v2"
 
@@ -32,9 +33,11 @@ func (t *ParserTestSuite) SetupTest() {
 	err = builder.Add(
 		Definition,
 		DefinitionContext,
+		DefinitionClock,
 		DefinitionScope,
 		DefinitionCommandTree,
 		logger.Definition,
+		terminal.DefinitionBuffer,
 	)
 	t.Require().NoError(err)
 	t.ctn = builder.Build()
@@ -62,6 +62,8 @@ type Response struct {
 	ctxType ContextType
 	object  Object
 	err     error
+	secret  bool
+	replay  []models.Rune
 }
 
 func (r *Response) Expression() Expression {
@@ -76,6 +78,16 @@ func (r *Response) Action() ResponseAction {
 	return r.action
 }
 
+// Secret reports whether a secret flag has been used.
+func (r *Response) Secret() bool {
+	return r.secret
+}
+
+// Replay returns the runes the new expression gets before the current one.
+func (r *Response) Replay() []models.Rune {
+	return r.replay
+}
+
 func (r *Response) ContextType() ContextType {
 	return r.ctxType
 }
@@ -104,6 +116,16 @@ func (r *Response) WithError(err error) *Response {
 	return r
 }
 
+func (r *Response) WithSecret(secret bool) *Response {
+	r.secret = secret
+	return r
+}
+
+func (r *Response) WithReplay(runes ...models.Rune) *Response {
+	r.replay = runes
+	return r
+}
+
 func (r *Response) WithContextType(ctxType ContextType) *Response {
 	r.ctxType = ctxType
 	return r
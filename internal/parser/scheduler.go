@@ -0,0 +1,246 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNoTask     = errors.New("no such task")
+	ErrTaskExists = errors.New("task already exists")
+)
+
+// Task is an entry of the scheduler. It runs at Start and then every
+// Interval, a task without an interval runs once.
+type Task struct {
+	Name     string
+	Start    time.Time
+	Interval time.Duration
+	OnEvent  string
+	Disabled bool
+	Owner    string
+
+	RunCount  int
+	LastError string
+	NextRun   time.Time
+
+	scope SystemContext
+	run   func(ctx SystemContext) error
+}
+
+// Scheduler runs the tasks as background jobs when they are due. It is
+// shared by all the scopes of a root scope.
+type Scheduler struct {
+	ctx     context.Context
+	clock   Clock
+	jobs    *Jobs
+	mu      sync.Mutex
+	tasks   []*Task
+	changed chan struct{}
+	once    sync.Once
+}
+
+// NewScheduler returns a scheduler without tasks, it stops with ctx.
+func NewScheduler(ctx context.Context, clock Clock, jobs *Jobs) *Scheduler {
+	return &Scheduler{
+		ctx:     ctx,
+		clock:   clock,
+		jobs:    jobs,
+		changed: make(chan struct{}, 1),
+	}
+}
+
+// Now returns the time of the scheduler's clock.
+func (s *Scheduler) Now() time.Time {
+	return s.clock.Now()
+}
+
+// Add schedules the task, run is called in a scope of its own every time the
+// task is due. A task without a start runs an interval after it is added.
+func (s *Scheduler) Add(ctx SystemContext, task Task, run func(ctx SystemContext) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.find(task.Name) != nil {
+		return ErrTaskExists
+	}
+
+	now := s.clock.Now()
+	if task.Start.IsZero() {
+		task.Start = now.Add(task.Interval)
+	}
+
+	added := task
+	added.Owner = ctx.User()
+	added.NextRun = nextRun(task.Start, task.Interval, now)
+	added.scope = ctx
+	added.run = run
+
+	s.tasks = append(s.tasks, &added)
+	s.once.Do(func() {
+		go s.loop()
+	})
+	s.notify()
+
+	return nil
+}
+
+// List returns a copy of the tasks in the order they have been added.
+func (s *Scheduler) List() []Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tasks := make([]Task, 0, len(s.tasks))
+	for _, task := range s.tasks {
+		tasks = append(tasks, *task)
+	}
+
+	return tasks
+}
+
+// SetDisabled stops or resumes the runs of the task.
+func (s *Scheduler) SetDisabled(name string, disabled bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task := s.find(name)
+	if task == nil {
+		return ErrNoTask
+	}
+
+	task.Disabled = disabled
+	if !disabled {
+		// the runs missed while the task was disabled are skipped
+		task.NextRun = nextRun(task.NextRun, task.Interval, s.clock.Now())
+	}
+
+	s.notify()
+
+	return nil
+}
+
+// Remove drops the task, a run in progress isn't stopped.
+func (s *Scheduler) Remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, task := range s.tasks {
+		if task.Name == name {
+			s.tasks = append(s.tasks[:i], s.tasks[i+1:]...)
+			s.notify()
+			return nil
+		}
+	}
+
+	return ErrNoTask
+}
+
+func (s *Scheduler) find(name string) *Task {
+	for _, task := range s.tasks {
+		if task.Name == name {
+			return task
+		}
+	}
+
+	return nil
+}
+
+// notify wakes the loop up to look at the changed tasks.
+func (s *Scheduler) notify() {
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Scheduler) loop() {
+	for {
+		var timer <-chan time.Time
+		if next, ok := s.next(); ok {
+			timer = s.clock.After(next.Sub(s.clock.Now()))
+		}
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-s.changed:
+		case <-timer:
+			s.runDue()
+		}
+	}
+}
+
+// next returns the earliest run of the enabled tasks.
+func (s *Scheduler) next() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var next time.Time
+	for _, task := range s.tasks {
+		if task.Disabled || task.NextRun.IsZero() {
+			continue
+		}
+
+		if next.IsZero() || task.NextRun.Before(next) {
+			next = task.NextRun
+		}
+	}
+
+	return next, !next.IsZero()
+}
+
+// runDue starts the tasks whose time has come and moves them to their next
+// run.
+func (s *Scheduler) runDue() {
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	var due []*Task
+	for _, task := range s.tasks {
+		if task.Disabled || task.NextRun.IsZero() || task.NextRun.After(now) {
+			continue
+		}
+
+		task.RunCount++
+		task.NextRun = nextRun(task.NextRun, task.Interval, now.Add(time.Nanosecond))
+		due = append(due, task)
+	}
+	s.mu.Unlock()
+
+	for _, task := range due {
+		task := task
+		s.jobs.Start(task.scope, task.OnEvent, func(ctx SystemContext) error {
+			err := task.run(ctx)
+			s.finish(task, err)
+			return err
+		})
+	}
+}
+
+func (s *Scheduler) finish(task *Task, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task.LastError = ""
+	if err != nil {
+		task.LastError = err.Error()
+	}
+}
+
+// nextRun returns the first run from start on which isn't before now, zero if
+// a task without an interval has already run.
+func nextRun(start time.Time, interval time.Duration, now time.Time) time.Time {
+	if !start.Before(now) {
+		return start
+	}
+
+	if interval <= 0 {
+		return time.Time{}
+	}
+
+	missed := (now.Sub(start) + interval - 1) / interval
+
+	return start.Add(missed * interval)
+}
@@ -0,0 +1,120 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/blkmlk/microshell/internal/logger"
+	"github.com/blkmlk/microshell/internal/terminal"
+	"github.com/sarulabs/di/v2"
+	"github.com/stretchr/testify/suite"
+)
+
+type schedulerTestSuite struct {
+	suite.Suite
+	ctx   SystemContext
+	clock *fakeClock
+	start time.Time
+}
+
+func TestScheduler(t *testing.T) {
+	suite.Run(t, new(schedulerTestSuite))
+}
+
+func (t *schedulerTestSuite) SetupTest() {
+	t.start = time.Date(2021, 8, 20, 10, 0, 0, 0, time.UTC)
+	t.clock = newFakeClock(t.start)
+
+	builder, err := di.NewBuilder()
+	t.Require().NoError(err)
+
+	err = builder.Add(
+		DefinitionContext,
+		di.Def{
+			Name: DefinitionNameClock,
+			Build: func(ctn di.Container) (interface{}, error) {
+				return t.clock, nil
+			},
+		},
+		DefinitionScope,
+		DefinitionCommandTree,
+		logger.Definition,
+		terminal.DefinitionBuffer,
+	)
+	t.Require().NoError(err)
+
+	t.ctx = builder.Build().Get(DefinitionNameRootScope).(SystemContext).WithUser("admin")
+}
+
+func (t *schedulerTestSuite) TestRuns() {
+	scheduler := t.ctx.Scheduler()
+
+	backups := make(chan SystemContext, 10)
+	err := scheduler.Add(t.ctx, Task{Name: "backup", Interval: time.Minute, OnEvent: "{ :put 1 }"}, func(ctx SystemContext) error {
+		backups <- ctx
+		return nil
+	})
+	t.Require().NoError(err)
+
+	once := make(chan struct{}, 10)
+	err = scheduler.Add(t.ctx, Task{Name: "once", Start: t.start.Add(30 * time.Second)}, func(ctx SystemContext) error {
+		once <- struct{}{}
+		return errors.New("boom")
+	})
+	t.Require().NoError(err)
+
+	t.Require().ErrorIs(scheduler.Add(t.ctx, Task{Name: "once"}, nil), ErrTaskExists)
+
+	t.clock.waitFor(t.start.Add(30 * time.Second))
+	t.clock.Advance(30 * time.Second)
+	<-once
+
+	t.Require().Eventually(func() bool {
+		return scheduler.List()[1].LastError == "boom"
+	}, time.Second, time.Millisecond)
+
+	task := scheduler.List()[1]
+	t.Require().Equal(1, task.RunCount)
+	t.Require().True(task.NextRun.IsZero())
+	t.Require().Equal("admin", task.Owner)
+
+	t.clock.waitFor(t.start.Add(time.Minute))
+	t.clock.Advance(30 * time.Second)
+
+	scope := <-backups
+	t.Require().NotSame(t.ctx.Buffer(), scope.Buffer())
+
+	task = scheduler.List()[0]
+	t.Require().Equal(1, task.RunCount)
+	t.Require().Equal(t.start.Add(2*time.Minute), task.NextRun)
+	t.Require().Equal("{ :put 1 }", task.OnEvent)
+
+	// a disabled task skips its runs
+	t.Require().NoError(scheduler.SetDisabled("backup", true))
+	t.clock.Advance(90 * time.Second)
+	t.Require().Equal(1, scheduler.List()[0].RunCount)
+
+	t.Require().NoError(scheduler.SetDisabled("backup", false))
+	t.Require().Equal(t.start.Add(3*time.Minute), scheduler.List()[0].NextRun)
+
+	t.clock.waitFor(t.start.Add(3 * time.Minute))
+	t.clock.Advance(30 * time.Second)
+	<-backups
+	t.Require().Equal(2, scheduler.List()[0].RunCount)
+
+	t.Require().NoError(scheduler.Remove("once"))
+	t.Require().ErrorIs(scheduler.Remove("once"), ErrNoTask)
+	t.Require().ErrorIs(scheduler.SetDisabled("once", true), ErrNoTask)
+	t.Require().Len(scheduler.List(), 1)
+
+	// the scheduler is shared by the scopes of the root
+	t.Require().Same(scheduler, t.ctx.New().Copy().Scheduler())
+}
+
+func (t *schedulerTestSuite) TestNextRun() {
+	t.Require().Equal(t.start, nextRun(t.start, time.Hour, t.start))
+	t.Require().Equal(t.start.Add(2*time.Hour), nextRun(t.start, time.Hour, t.start.Add(90*time.Minute)))
+	t.Require().Equal(t.start.Add(time.Hour), nextRun(t.start, time.Hour, t.start.Add(time.Hour)))
+	t.Require().True(nextRun(t.start, 0, t.start.Add(time.Second)).IsZero())
+}
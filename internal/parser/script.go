@@ -0,0 +1,170 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MaxScriptDepth is how deep the stored scripts may run each other.
+const MaxScriptDepth = 16
+
+var (
+	ErrNoScript     = errors.New("no such script")
+	ErrScriptExists = errors.New("script already exists")
+	ErrScriptDepth  = fmt.Errorf("scripts nested deeper than %d", MaxScriptDepth)
+)
+
+// Script is a stored command list, it is parsed again on every run.
+type Script struct {
+	Name     string
+	Owner    string
+	Source   string
+	LastRun  time.Time
+	RunCount int
+}
+
+// ScriptError is the failure of a stored script. The position of the failure
+// in the script is left out, it doesn't point into the text which has run
+// the script.
+type ScriptError struct {
+	Name string
+	Err  error
+}
+
+func (e *ScriptError) Error() string {
+	return fmt.Sprintf("script %s: %v", e.Name, e.Err)
+}
+
+func (e *ScriptError) Unwrap() error {
+	return cause(e.Err)
+}
+
+// cause returns the failure without the command which has failed.
+func cause(err error) error {
+	if execErr, ok := err.(*ExecError); ok {
+		return execErr.Err
+	}
+
+	return err
+}
+
+// depthKey keeps how deep the scripts run in the context of the execution.
+type depthKey struct{}
+
+// Scripts is the repository of the stored scripts, it is shared by all the
+// scopes of a root scope.
+type Scripts struct {
+	clock   Clock
+	mu      sync.Mutex
+	scripts []*Script
+}
+
+// NewScripts returns an empty repository.
+func NewScripts(clock Clock) *Scripts {
+	return &Scripts{clock: clock}
+}
+
+// Add stores the source once it parses, a failure to parse is a
+// ScriptError.
+func (s *Scripts) Add(ctx SystemContext, name, source string) error {
+	if _, err := Parse(ctx, source); err != nil {
+		return &ScriptError{Name: name, Err: fmt.Errorf("syntax error: %w", err)}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.find(name) != nil {
+		return ErrScriptExists
+	}
+
+	s.scripts = append(s.scripts, &Script{
+		Name:   name,
+		Owner:  ctx.User(),
+		Source: source,
+	})
+
+	return nil
+}
+
+// List returns a copy of the scripts in the order they have been added.
+func (s *Scripts) List() []Script {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scripts := make([]Script, 0, len(s.scripts))
+	for _, script := range s.scripts {
+		scripts = append(scripts, *script)
+	}
+
+	return scripts
+}
+
+// Remove drops the script, a run in progress isn't stopped.
+func (s *Scripts) Remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, script := range s.scripts {
+		if script.Name == name {
+			s.scripts = append(s.scripts[:i], s.scripts[i+1:]...)
+			return nil
+		}
+	}
+
+	return ErrNoScript
+}
+
+// Run parses the script and evaluates it in a new scope of ctx. A script run
+// by a script counts towards MaxScriptDepth.
+func (s *Scripts) Run(ctx SystemContext, name string) (Value, error) {
+	depth, _ := ctx.Value(depthKey{}).(int)
+	if depth >= MaxScriptDepth {
+		return nil, ErrScriptDepth
+	}
+
+	s.mu.Lock()
+	script := s.find(name)
+	if script == nil {
+		s.mu.Unlock()
+		return nil, ErrNoScript
+	}
+
+	script.LastRun = s.clock.Now()
+	script.RunCount++
+	source := script.Source
+	s.mu.Unlock()
+
+	exp, err := Parse(ctx, source)
+	if err != nil {
+		return nil, &ScriptError{Name: name, Err: err}
+	}
+
+	scope := ctx.New()
+	scope = scope.WithContext(context.WithValue(scope.Ctx(), depthKey{}, depth+1))
+
+	value, err := exp.Value(scope)
+	if err != nil {
+		// the limit is reported once by the script which has run first
+		if errors.Is(err, ErrScriptDepth) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return nil, cause(err)
+		}
+
+		return nil, &ScriptError{Name: name, Err: err}
+	}
+
+	return value, nil
+}
+
+func (s *Scripts) find(name string) *Script {
+	for _, script := range s.scripts {
+		if script.Name == name {
+			return script
+		}
+	}
+
+	return nil
+}
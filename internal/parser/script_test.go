@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/blkmlk/microshell/internal/logger"
+	"github.com/blkmlk/microshell/internal/terminal"
+	"github.com/sarulabs/di/v2"
+	"github.com/stretchr/testify/suite"
+)
+
+type scriptsTestSuite struct {
+	suite.Suite
+	ctx SystemContext
+	now time.Time
+}
+
+func TestScripts(t *testing.T) {
+	suite.Run(t, new(scriptsTestSuite))
+}
+
+func (t *scriptsTestSuite) SetupTest() {
+	t.now = time.Date(2021, 8, 20, 10, 0, 0, 0, time.UTC)
+
+	builder, err := di.NewBuilder()
+	t.Require().NoError(err)
+
+	err = builder.Add(
+		DefinitionContext,
+		di.Def{
+			Name: DefinitionNameClock,
+			Build: func(ctn di.Container) (interface{}, error) {
+				return newFakeClock(t.now), nil
+			},
+		},
+		DefinitionScope,
+		DefinitionCommandTree,
+		logger.Definition,
+		terminal.DefinitionBuffer,
+	)
+	t.Require().NoError(err)
+
+	t.ctx = builder.Build().Get(DefinitionNameRootScope).(SystemContext).WithUser("admin")
+}
+
+func (t *scriptsTestSuite) TestScripts() {
+	scripts := t.ctx.Scripts()
+
+	t.Require().NoError(scripts.Add(t.ctx, "sum", "(1 + 2)"))
+	t.Require().ErrorIs(scripts.Add(t.ctx, "sum", "(1 + 2)"), ErrScriptExists)
+	t.Require().ErrorIs(scripts.Add(t.ctx, "bad", "(1 +"), ErrNotFinished)
+
+	value, err := scripts.Run(t.ctx, "sum")
+	t.Require().NoError(err)
+	t.Require().Equal(3, value.Number())
+
+	list := scripts.List()
+	t.Require().Len(list, 1)
+	t.Require().Equal("admin", list[0].Owner)
+	t.Require().Equal("(1 + 2)", list[0].Source)
+	t.Require().Equal(1, list[0].RunCount)
+	t.Require().Equal(t.now, list[0].LastRun)
+
+	_, err = scripts.Run(t.ctx, "missing")
+	t.Require().ErrorIs(err, ErrNoScript)
+
+	deep := t.ctx.New()
+	deep = deep.WithContext(context.WithValue(deep.Ctx(), depthKey{}, MaxScriptDepth))
+	_, err = scripts.Run(deep, "sum")
+	t.Require().ErrorIs(err, ErrScriptDepth)
+
+	t.Require().NoError(scripts.Remove("sum"))
+	t.Require().ErrorIs(scripts.Remove("sum"), ErrNoScript)
+	t.Require().Empty(scripts.List())
+
+	// the scripts are shared by the scopes of the root
+	t.Require().Same(scripts, t.ctx.New().Copy().Scripts())
+}
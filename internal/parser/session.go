@@ -0,0 +1,32 @@
+package parser
+
+import "sync"
+
+// Session keeps the state of the commands for a single shell or runner,
+// e.g. the numbers of the last print of a menu. The scopes of a root scope
+// share its session.
+type Session struct {
+	mu     sync.Mutex
+	values map[interface{}]interface{}
+}
+
+// NewSession returns a session without values.
+func NewSession() *Session {
+	return &Session{values: make(map[interface{}]interface{})}
+}
+
+// Get returns the value stored under key, nil if there is none.
+func (s *Session) Get(key interface{}) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.values[key]
+}
+
+// Set stores the value under key, the key must be comparable.
+func (s *Session) Set(key, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values[key] = value
+}
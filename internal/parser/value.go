@@ -1,10 +1,29 @@
 package parser
 
 type Valuer interface {
-	Value(ctx SystemContext) Value
+	Value(ctx SystemContext) (Value, error)
 }
 
+// ValueKind is the type of a value, the names are the ones :typeof prints.
+type ValueKind string
+
+const (
+	KindNothing   ValueKind = "nothing"
+	KindNil       ValueKind = "nil"
+	KindString    ValueKind = "str"
+	KindNumber    ValueKind = "num"
+	KindBool      ValueKind = "bool"
+	KindArray     ValueKind = "array"
+	KindIP        ValueKind = "ip"
+	KindIPPrefix  ValueKind = "ip-prefix"
+	KindIP6       ValueKind = "ip6"
+	KindIP6Prefix ValueKind = "ip6-prefix"
+	KindTime      ValueKind = "time"
+)
+
 type Value interface {
+	Kind() ValueKind
+
 	Bool() bool
 	Number() int
 	String() string
@@ -19,5 +38,79 @@ type Value interface {
 }
 
 var (
-	NullValue = NewStringValue("")
+	// NullValue is the value of an unset variable or an empty result.
+	NullValue Value = newValue(KindNothing, "")
+	// NilValue is returned when there is no answer, e.g. a failed conversion.
+	NilValue Value = newValue(KindNil, "")
 )
+
+// parseLiteral returns the address, the network or the duration written as
+// an unquoted literal.
+func parseLiteral(s string) (Value, bool) {
+	if v, ok := parsePrefix(s); ok {
+		return v, true
+	}
+
+	if v, ok := parseIP(s); ok {
+		return v, true
+	}
+
+	if v, ok := parseTime(s); ok {
+		return v, true
+	}
+
+	return nil, false
+}
+
+// literal returns the typed value of an unquoted literal, the other values
+// are returned as they are.
+func literal(v Value) Value {
+	s, ok := v.(*stdExpression)
+	if !ok {
+		return v
+	}
+
+	typed := s.typed
+	if s.kind == "" {
+		_, typed = s.parseKind()
+	}
+
+	if typed != nil {
+		return typed
+	}
+
+	return v
+}
+
+// asTyped returns the typed value written in the text of a string, e.g.
+// of a quoted flag value. The other values are returned as they are.
+func asTyped(v Value) Value {
+	if s, ok := v.(*stdExpression); ok && s.Kind() == KindString {
+		if typed, ok := parseLiteral(s.String()); ok {
+			return typed
+		}
+	}
+
+	return literal(v)
+}
+
+// ParseValue reads the text as an unquoted word: a number, a bool, an
+// address, a network, a duration or else a string.
+func ParseValue(text string) Value {
+	v := new(stdExpression)
+	v.value.WriteString(text)
+	v.settle()
+
+	return literal(v)
+}
+
+// IsYes reports whether a flag is switched on: true, yes or a number other
+// than 0.
+func IsYes(v Value) bool {
+	switch v.String() {
+	case "true", "yes":
+		return true
+	}
+
+	return v.IsNumber() && v.Number() != 0
+}
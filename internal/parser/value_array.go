@@ -0,0 +1,187 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+)
+
+// ArrayItem is an element of an array, the listed elements have no key.
+type ArrayItem struct {
+	Key   string
+	Value Value
+}
+
+// Array is a value holding a list of values, a key-value map or both.
+type Array interface {
+	Value
+	Len() int
+	Items() []ArrayItem
+	Index(i int) Value
+	Get(key string) (Value, bool)
+}
+
+var _ Array = &arrayValue{}
+
+type arrayValue struct {
+	items []ArrayItem
+}
+
+// NewArrayValue returns a list of the values.
+func NewArrayValue(values ...Value) Value {
+	items := make([]ArrayItem, 0, len(values))
+	for _, v := range values {
+		items = append(items, ArrayItem{Value: v})
+	}
+
+	return newArray(items)
+}
+
+// NewKeyedArrayValue returns an array mapping the keys to the values.
+func NewKeyedArrayValue(values map[string]Value) Value {
+	items := make([]ArrayItem, 0, len(values))
+	for k, v := range values {
+		items = append(items, ArrayItem{Key: k, Value: v})
+	}
+
+	return newArray(items)
+}
+
+// NewArrayValueFromItems returns an array of the listed and the keyed items.
+func NewArrayValueFromItems(items []ArrayItem) Value {
+	return newArray(items)
+}
+
+// newArray puts the listed elements first and orders the keyed ones by the
+// key, the last element of a key replaces the previous ones.
+func newArray(items []ArrayItem) *arrayValue {
+	a := &arrayValue{items: make([]ArrayItem, 0, len(items))}
+	keys := make(map[string]int)
+
+	for _, item := range items {
+		if item.Value == nil {
+			item.Value = NullValue
+		}
+
+		if item.Key == "" {
+			a.items = append(a.items, item)
+			continue
+		}
+
+		if i, ok := keys[item.Key]; ok {
+			a.items[i] = item
+			continue
+		}
+
+		keys[item.Key] = len(a.items)
+		a.items = append(a.items, item)
+	}
+
+	sort.SliceStable(a.items, func(i, j int) bool {
+		ki, kj := a.items[i].Key, a.items[j].Key
+		if ki == "" || kj == "" {
+			return ki == "" && kj != ""
+		}
+
+		return ki < kj
+	})
+
+	return a
+}
+
+func (a *arrayValue) Len() int {
+	return len(a.items)
+}
+
+func (a *arrayValue) Items() []ArrayItem {
+	return append([]ArrayItem(nil), a.items...)
+}
+
+// Index returns the element at the position i, nil if there is none.
+func (a *arrayValue) Index(i int) Value {
+	if i < 0 || i >= len(a.items) {
+		return nil
+	}
+
+	return a.items[i].Value
+}
+
+func (a *arrayValue) Get(key string) (Value, bool) {
+	for _, item := range a.items {
+		if item.Key == key {
+			return item.Value, true
+		}
+	}
+
+	return nil, false
+}
+
+func (a *arrayValue) Kind() ValueKind {
+	return KindArray
+}
+
+func (a *arrayValue) Bool() bool {
+	return len(a.items) > 0
+}
+
+func (a *arrayValue) Number() int {
+	return 0
+}
+
+// String joins the elements with semicolons, the nested arrays are put in
+// braces.
+func (a *arrayValue) String() string {
+	var b strings.Builder
+
+	for i, item := range a.items {
+		if i > 0 {
+			b.WriteByte(';')
+		}
+
+		if item.Key != "" {
+			b.WriteString(item.Key + "=")
+		}
+
+		if _, ok := item.Value.(Array); ok {
+			b.WriteString("{" + item.Value.String() + "}")
+		} else {
+			b.WriteString(item.Value.String())
+		}
+	}
+
+	return b.String()
+}
+
+func (a *arrayValue) IsBool() bool {
+	return false
+}
+
+func (a *arrayValue) IsString() bool {
+	return false
+}
+
+func (a *arrayValue) IsNumber() bool {
+	return false
+}
+
+func (a *arrayValue) Equal(v Value) bool {
+	other, ok := v.(Array)
+	if !ok || other.Len() != a.Len() {
+		return false
+	}
+
+	for i, item := range other.Items() {
+		if item.Key != a.items[i].Key || !a.items[i].Value.Equal(item.Value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (a *arrayValue) Less(v Value) bool {
+	return false
+}
+
+func (a *arrayValue) Greater(v Value) bool {
+	return false
+}
@@ -0,0 +1,292 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"strconv"
+	"strings"
+)
+
+var _ Value = &ipValue{}
+var _ Value = &prefixValue{}
+
+// ipValue is an IPv4 or an IPv6 address.
+type ipValue struct {
+	ip net.IP
+}
+
+// NewIPValue returns the address as a value, IPv4 addresses are kept in their
+// 4-byte form.
+func NewIPValue(ip net.IP) Value {
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+
+	return &ipValue{ip: ip}
+}
+
+// IP returns the address of the value, nil if it holds none.
+func IP(v Value) net.IP {
+	switch t := asTyped(v).(type) {
+	case *ipValue:
+		return t.ip
+	}
+
+	return nil
+}
+
+func (i *ipValue) Is6() bool {
+	return len(i.ip) == net.IPv6len
+}
+
+func (i *ipValue) Kind() ValueKind {
+	if i.Is6() {
+		return KindIP6
+	}
+
+	return KindIP
+}
+
+func (i *ipValue) Bool() bool {
+	return !i.ip.IsUnspecified()
+}
+
+// Number returns an IPv4 address as an integer, 0 for IPv6.
+func (i *ipValue) Number() int {
+	if i.Is6() {
+		return 0
+	}
+
+	return int(binary.BigEndian.Uint32(i.ip))
+}
+
+func (i *ipValue) String() string {
+	return i.ip.String()
+}
+
+func (i *ipValue) IsBool() bool {
+	return false
+}
+
+func (i *ipValue) IsString() bool {
+	return false
+}
+
+func (i *ipValue) IsNumber() bool {
+	return false
+}
+
+func (i *ipValue) Equal(v Value) bool {
+	other, ok := literal(v).(*ipValue)
+	return ok && i.ip.Equal(other.ip)
+}
+
+func (i *ipValue) Less(v Value) bool {
+	other, ok := literal(v).(*ipValue)
+	return ok && bytes.Compare(i.ip.To16(), other.ip.To16()) < 0
+}
+
+func (i *ipValue) Greater(v Value) bool {
+	other, ok := literal(v).(*ipValue)
+	return ok && bytes.Compare(i.ip.To16(), other.ip.To16()) > 0
+}
+
+// add moves the address by n, it wraps around the address space.
+func (i *ipValue) add(n int) *ipValue {
+	ip := make(net.IP, len(i.ip))
+	copy(ip, i.ip)
+
+	carry := n
+	for j := len(ip) - 1; j >= 0 && carry != 0; j-- {
+		sum := int(ip[j]) + carry%256
+		carry /= 256
+
+		switch {
+		case sum > 255:
+			sum -= 256
+			carry++
+		case sum < 0:
+			sum += 256
+			carry--
+		}
+
+		ip[j] = byte(sum)
+	}
+
+	return &ipValue{ip: ip}
+}
+
+// sub returns the distance between the addresses of the same family, the
+// IPv6 ones are compared by their lower 64 bits.
+func (i *ipValue) sub(other *ipValue) (int, bool) {
+	if i.Is6() != other.Is6() {
+		return 0, false
+	}
+
+	if !i.Is6() {
+		return i.Number() - other.Number(), true
+	}
+
+	return int(binary.BigEndian.Uint64(i.ip[8:]) - binary.BigEndian.Uint64(other.ip[8:])), true
+}
+
+// bitwise applies &, | or ^ byte by byte to the addresses of the same family.
+func (i *ipValue) bitwise(op Operator, other *ipValue) *ipValue {
+	ip := make(net.IP, len(i.ip))
+
+	for j := range ip {
+		switch op {
+		case OperatorBitAnd:
+			ip[j] = i.ip[j] & other.ip[j]
+		case OperatorBitOr:
+			ip[j] = i.ip[j] | other.ip[j]
+		case OperatorBitXor:
+			ip[j] = i.ip[j] ^ other.ip[j]
+		}
+	}
+
+	return &ipValue{ip: ip}
+}
+
+// not inverts every bit of the address.
+func (i *ipValue) not() *ipValue {
+	ip := make(net.IP, len(i.ip))
+
+	for j := range ip {
+		ip[j] = ^i.ip[j]
+	}
+
+	return &ipValue{ip: ip}
+}
+
+// prefixValue is an address with the length of its network, e.g. 10.0.0.0/8.
+type prefixValue struct {
+	ip      net.IP
+	network *net.IPNet
+}
+
+// NewIPPrefixValue returns the network as a value.
+func NewIPPrefixValue(network *net.IPNet) Value {
+	ip := network.IP
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+
+	return &prefixValue{ip: ip, network: &net.IPNet{IP: ip.Mask(network.Mask), Mask: network.Mask}}
+}
+
+// IPNet returns the network of the value, nil if it holds none.
+func IPNet(v Value) *net.IPNet {
+	switch t := asTyped(v).(type) {
+	case *prefixValue:
+		return t.network
+	}
+
+	return nil
+}
+
+func (p *prefixValue) Is6() bool {
+	return len(p.ip) == net.IPv6len
+}
+
+// Contains reports whether the address or the whole network is in the
+// prefix.
+func (p *prefixValue) Contains(v Value) bool {
+	switch t := literal(v).(type) {
+	case *ipValue:
+		return t.Is6() == p.Is6() && p.network.Contains(t.ip)
+	case *prefixValue:
+		ones, _ := p.network.Mask.Size()
+		inner, _ := t.network.Mask.Size()
+		return t.Is6() == p.Is6() && inner >= ones && p.network.Contains(t.network.IP)
+	}
+
+	return false
+}
+
+func (p *prefixValue) Kind() ValueKind {
+	if p.Is6() {
+		return KindIP6Prefix
+	}
+
+	return KindIPPrefix
+}
+
+func (p *prefixValue) Bool() bool {
+	return true
+}
+
+func (p *prefixValue) Number() int {
+	return 0
+}
+
+func (p *prefixValue) String() string {
+	ones, _ := p.network.Mask.Size()
+	return p.ip.String() + "/" + strconv.Itoa(ones)
+}
+
+func (p *prefixValue) IsBool() bool {
+	return false
+}
+
+func (p *prefixValue) IsString() bool {
+	return false
+}
+
+func (p *prefixValue) IsNumber() bool {
+	return false
+}
+
+func (p *prefixValue) Equal(v Value) bool {
+	other, ok := literal(v).(*prefixValue)
+	return ok && p.String() == other.String()
+}
+
+func (p *prefixValue) Less(v Value) bool {
+	return false
+}
+
+func (p *prefixValue) Greater(v Value) bool {
+	return false
+}
+
+// parseIP returns the address written as a literal.
+func parseIP(s string) (*ipValue, bool) {
+	if !strings.ContainsAny(s, ".:") {
+		return nil, false
+	}
+
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, false
+	}
+
+	if strings.Contains(s, ":") {
+		return &ipValue{ip: ip.To16()}, true
+	}
+
+	return &ipValue{ip: ip.To4()}, true
+}
+
+// parsePrefix returns the network written as a literal, the address keeps
+// its host bits.
+func parsePrefix(s string) (*prefixValue, bool) {
+	if !strings.Contains(s, "/") {
+		return nil, false
+	}
+
+	ip, network, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, false
+	}
+
+	v := NewIPPrefixValue(network).(*prefixValue)
+	if strings.Contains(s, ":") {
+		v.ip = ip.To16()
+	} else {
+		v.ip = ip.To4()
+	}
+
+	return v, true
+}
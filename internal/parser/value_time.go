@@ -0,0 +1,193 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var _ Value = &timeValue{}
+
+const (
+	day  = 24 * time.Hour
+	week = 7 * day
+)
+
+var timeUnits = map[string]time.Duration{
+	"w":  week,
+	"d":  day,
+	"h":  time.Hour,
+	"m":  time.Minute,
+	"s":  time.Second,
+	"ms": time.Millisecond,
+}
+
+// timeValue is a duration written as 1h30m or 1d00:05:00.
+type timeValue struct {
+	d time.Duration
+}
+
+func NewTimeValue(d time.Duration) Value {
+	return &timeValue{d: d}
+}
+
+// Duration returns the duration of the value, a number is taken as seconds.
+func Duration(v Value) (time.Duration, bool) {
+	switch t := asTyped(v).(type) {
+	case *timeValue:
+		return t.d, true
+	}
+
+	if v.IsNumber() {
+		return time.Duration(v.Number()) * time.Second, true
+	}
+
+	return 0, false
+}
+
+func (t *timeValue) Kind() ValueKind {
+	return KindTime
+}
+
+func (t *timeValue) Bool() bool {
+	return t.d != 0
+}
+
+// Number returns the duration in seconds.
+func (t *timeValue) Number() int {
+	return int(t.d / time.Second)
+}
+
+// String formats the duration as [Nw][Nd]HH:MM:SS[.mmm].
+func (t *timeValue) String() string {
+	var b strings.Builder
+
+	d := t.d
+	if d < 0 {
+		b.WriteByte('-')
+		d = -d
+	}
+
+	if w := d / week; w > 0 {
+		fmt.Fprintf(&b, "%dw", w)
+		d -= w * week
+	}
+
+	if n := d / day; n > 0 {
+		fmt.Fprintf(&b, "%dd", n)
+		d -= n * day
+	}
+
+	h, m, s := d/time.Hour, d/time.Minute%60, d/time.Second%60
+	fmt.Fprintf(&b, "%02d:%02d:%02d", h, m, s)
+
+	if ms := d / time.Millisecond % 1000; ms > 0 {
+		fmt.Fprintf(&b, ".%03d", ms)
+	}
+
+	return b.String()
+}
+
+func (t *timeValue) IsBool() bool {
+	return false
+}
+
+func (t *timeValue) IsString() bool {
+	return false
+}
+
+func (t *timeValue) IsNumber() bool {
+	return false
+}
+
+func (t *timeValue) Equal(v Value) bool {
+	other, ok := literal(v).(*timeValue)
+	return ok && t.d == other.d
+}
+
+func (t *timeValue) Less(v Value) bool {
+	d, ok := Duration(v)
+	return ok && t.d < d
+}
+
+func (t *timeValue) Greater(v Value) bool {
+	d, ok := Duration(v)
+	return ok && t.d > d
+}
+
+// parseTime returns the duration written with units (1w2d3h4m5s, 500ms)
+// and/or a clock (1d02:00:00, 00:05:00.250).
+func parseTime(s string) (*timeValue, bool) {
+	if s == "" || (!strings.ContainsAny(s, "wdhms") && !strings.Contains(s, ":")) {
+		return nil, false
+	}
+
+	var d time.Duration
+
+	for s != "" {
+		i := 0
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+
+		if i == 0 {
+			return nil, false
+		}
+
+		if i < len(s) && s[i] == ':' {
+			clock, ok := parseClock(s)
+			if !ok {
+				return nil, false
+			}
+
+			return &timeValue{d: d + clock}, true
+		}
+
+		n, err := strconv.Atoi(s[:i])
+		if err != nil {
+			return nil, false
+		}
+
+		j := i
+		for j < len(s) && (s[j] < '0' || s[j] > '9') {
+			j++
+		}
+
+		unit, ok := timeUnits[s[i:j]]
+		if !ok {
+			return nil, false
+		}
+
+		d += time.Duration(n) * unit
+		s = s[j:]
+	}
+
+	return &timeValue{d: d}, true
+}
+
+// parseClock parses HH:MM:SS with optional fractional seconds.
+func parseClock(s string) (time.Duration, bool) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, false
+	}
+
+	h, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, false
+	}
+
+	m, err := strconv.Atoi(parts[1])
+	if err != nil || m >= 60 {
+		return 0, false
+	}
+
+	sec, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil || sec >= 60 || strings.ContainsAny(parts[2], "+-eE") {
+		return 0, false
+	}
+
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute +
+		time.Duration(sec*float64(time.Second)).Round(time.Millisecond), true
+}
@@ -46,8 +46,9 @@ func (i *variableIterator) NextOptions() *NextOptions {
 	merged := ""
 
 	if len(globalMerged) > 0 && len(localMerged) > 0 {
-		for i, r := range globalMerged {
-			if len(localMerged) > i && rune(localMerged[i]) == r {
+		local := []rune(localMerged)
+		for i, r := range []rune(globalMerged) {
+			if len(local) > i && local[i] == r {
 				merged += string(r)
 			}
 		}
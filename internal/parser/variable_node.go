@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"unicode/utf8"
+
 	"github.com/blkmlk/microshell/internal/models"
 )
 
@@ -66,7 +68,8 @@ func (n *variableNode) Add(key string, payload interface{}) {
 		Payload: payload,
 	}
 
-	firstRune := models.Rune(key[0])
+	first, _ := utf8.DecodeRuneInString(key)
+	firstRune := models.Rune(first)
 	n.runes[firstRune] = newChain.runes[firstRune]
 }
 
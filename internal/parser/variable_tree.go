@@ -1,13 +1,38 @@
 package parser
 
+import "sync"
+
+// globalScope is shared by all the copies of a variable tree. The nodes are
+// never changed after they are published, a new root replaces the old one.
+type globalScope struct {
+	mu   sync.RWMutex
+	root *variableNode
+}
+
+func (s *globalScope) Root() *variableNode {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.root
+}
+
+func (s *globalScope) Add(name string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	root := s.root.Copy()
+	root.Add(name, value)
+	s.root = root
+}
+
 type VariableTree struct {
-	global *variableNode
+	global *globalScope
 	local  *variableNode
 }
 
 func NewVariableTree() *VariableTree {
 	return &VariableTree{
-		global: NewVariableNode(),
+		global: &globalScope{root: NewVariableNode()},
 		local:  NewVariableNode(),
 	}
 }
@@ -43,7 +68,7 @@ func (t *VariableTree) get(name string) interface{} {
 		return n.variable.Payload
 	}
 
-	n = t.global.Get(name)
+	n = t.global.Root().Get(name)
 	if n != nil && n.variable != nil {
 		return n.variable.Payload
 	}
@@ -53,7 +78,7 @@ func (t *VariableTree) get(name string) interface{} {
 
 func (t *VariableTree) GetIterator() *variableIterator {
 	return &variableIterator{
-		currentGlobal: t.global,
+		currentGlobal: t.global.Root(),
 		currentLocal:  t.local,
 	}
 }
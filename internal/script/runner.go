@@ -0,0 +1,205 @@
+package script
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/blkmlk/microshell/internal/parser"
+	"github.com/blkmlk/microshell/internal/terminal"
+	"github.com/sarulabs/di/v2"
+)
+
+var (
+	ErrSyntax   = errors.New("syntax error")
+	ErrRuntime  = errors.New("runtime error")
+	ErrUnclosed = errors.New("unexpected end of script")
+)
+
+// Error points to the line and the column of the statement that failed.
+type Error struct {
+	Name   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %v", e.Name, e.Line, e.Column, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Runner executes scripts statement by statement without a terminal.
+type Runner struct {
+	root   parser.SystemContext
+	parser parser.Parser
+	buffer terminal.Buffer
+	out    io.Writer
+}
+
+// segment is a script line joined into the current statement.
+type segment struct {
+	line   int
+	offset int
+}
+
+type statement struct {
+	text     strings.Builder
+	length   int
+	segments []segment
+}
+
+func NewRunner(ctn di.Container, out io.Writer) *Runner {
+	return &Runner{
+		root:   ctn.Get(parser.DefinitionNameRootScope).(parser.SystemContext),
+		buffer: ctn.Get(terminal.DefinitionNameBuffer).(terminal.Buffer),
+		out:    out,
+	}
+}
+
+// RunString executes the given commands as a script named "command".
+func (r *Runner) RunString(s string) error {
+	return r.Run("command", strings.NewReader(s))
+}
+
+// Run reads the script line by line. Lines are joined while brackets or
+// quotes stay unclosed and every complete statement is executed right away.
+// The first failing statement stops the script. The statements share the
+// scope of the script, a local of one of them stays for the next ones.
+func (r *Runner) Run(name string, in io.Reader) error {
+	var (
+		st        *statement
+		separator string
+	)
+
+	r.parser = parser.NewScriptParser(r.root.New())
+
+	scanner := bufio.NewScanner(in)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.ReplaceAll(strings.TrimRight(scanner.Text(), "\r"), "\t", " ")
+		trimmed := strings.TrimSpace(text)
+
+		if st == nil {
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+
+			st = new(statement)
+		} else if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		} else {
+			st.text.WriteString(separator)
+			st.length += len([]rune(separator))
+		}
+
+		st.segments = append(st.segments, segment{line: line, offset: st.length})
+		st.text.WriteString(text)
+		st.length += len([]rune(text))
+
+		unclosed, err := r.exec(name, st)
+		if err != nil {
+			return err
+		}
+
+		switch unclosed {
+		case 0:
+			st = nil
+		case '(', '"':
+			separator = " "
+		default:
+			separator = "; "
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if st != nil {
+		last := st.segments[len(st.segments)-1]
+		return &Error{Name: name, Line: last.line, Column: st.length - last.offset + 1, Err: ErrUnclosed}
+	}
+
+	return nil
+}
+
+// exec parses and runs the statement. It returns the unclosed bracket if the
+// statement continues on the next line.
+func (r *Runner) exec(name string, st *statement) (rune, error) {
+	text := st.text.String()
+
+	resp := r.parser.ParseString(text)
+
+	if resp.Error != nil {
+		return 0, st.error(name, parser.SyntaxOffset(resp.Error, st.length), fmt.Errorf("%w: %v", ErrSyntax, resp.Error))
+	}
+
+	execResp, err := r.parser.Exec()
+
+	if err != nil {
+		// the output of the commands run before the failure is kept
+		r.flush()
+
+		offset := 0
+
+		var execErr *parser.ExecError
+		if errors.As(err, &execErr) {
+			offset = execErr.Span.Offset
+		}
+
+		return 0, st.error(name, offset, fmt.Errorf("%w: %v", ErrRuntime, err))
+	}
+
+	if execResp.Error != nil {
+		if errors.Is(execResp.Error, parser.ErrNotFinished) && execResp.UnclosedBrackets != 0 {
+			return rune(execResp.UnclosedBrackets), nil
+		}
+
+		return 0, st.error(name, parser.SyntaxOffset(execResp.Error, st.length), fmt.Errorf("%w: %v", ErrSyntax, execResp.Error))
+	}
+
+	r.flush()
+
+	return 0, nil
+}
+
+func (r *Runner) flush() {
+	for out, exists := r.buffer.Pop(); exists; out, exists = r.buffer.Pop() {
+		var text strings.Builder
+
+		for _, w := range out.Words(0, 0) {
+			text.WriteString(w.Text())
+		}
+
+		if text.Len() == 0 {
+			continue
+		}
+
+		if !strings.HasSuffix(text.String(), "\n") {
+			text.WriteString("\n")
+		}
+
+		_, _ = io.WriteString(r.out, text.String())
+	}
+}
+
+// error maps the rune offset in the joined statement back to the script.
+func (st *statement) error(name string, offset int, err error) *Error {
+	seg := st.segments[0]
+
+	for _, s := range st.segments {
+		if s.offset > offset {
+			break
+		}
+
+		seg = s
+	}
+
+	return &Error{Name: name, Line: seg.line, Column: offset - seg.offset + 1, Err: err}
+}
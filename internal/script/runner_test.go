@@ -0,0 +1,373 @@
+package script
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/blkmlk/microshell/internal/builtin"
+	"github.com/blkmlk/microshell/internal/logger"
+	"github.com/blkmlk/microshell/internal/parser"
+	"github.com/blkmlk/microshell/internal/terminal"
+	"github.com/sarulabs/di/v2"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestRunner(t *testing.T) {
+	suite.Run(t, new(runnerTestSuite))
+}
+
+type runnerTestSuite struct {
+	suite.Suite
+	out    *bytes.Buffer
+	runner *Runner
+}
+
+func (t *runnerTestSuite) SetupTest() {
+	builder, err := di.NewBuilder()
+	t.Require().NoError(err)
+
+	err = builder.Add(
+		parser.Definition,
+		parser.DefinitionContext,
+		parser.DefinitionClock,
+		parser.DefinitionScope,
+		logger.Definition,
+		terminal.DefinitionBuffer,
+		di.Def{
+			Name: parser.DefinitionNameCommandTree,
+			Build: func(ctn di.Container) (interface{}, error) {
+				return parser.List{Commands: builtin.Commands()}, nil
+			},
+		},
+	)
+	t.Require().NoError(err)
+
+	t.out = new(bytes.Buffer)
+	t.runner = NewRunner(builder.Build(), t.out)
+}
+
+func (t *runnerTestSuite) TestRunString() {
+	t.Require().NoError(t.runner.RunString(`:put 1; :put (2 + 3)`))
+	t.Require().Equal("1\n5\n", t.out.String())
+}
+
+func (t *runnerTestSuite) TestMultiLine() {
+	script := `
+# comment
+:global a 5
+{
+	:local b 7
+	:put ($a + $b)
+}
+
+:put (1 +
+	2)
+:put "done"
+`
+	t.Require().NoError(t.runner.Run("test.rsc", strings.NewReader(script)))
+	t.Require().Equal("12\n3\ndone\n", t.out.String())
+}
+
+func (t *runnerTestSuite) TestControlFlow() {
+	script := `
+:global n 0
+:local sq 0
+:if ($n = 0) do={ :put "zero" } else={ :put "other" }
+:while ($n < 2) do={
+	:global n ($n + 1)
+	:put $n
+}
+:for i from=3 to=1 do={ :put $i }
+:for i from=1 to=5 step=2 do={
+	:local sq ($i * $i)
+	:put $sq
+}
+:foreach x in=7 do={ :put $x }
+:do { :global n ($n + 1) } while=($n < 5)
+{ :if ($n = 5) do={ :put "five" } }
+:put $sq
+`
+	t.Require().NoError(t.runner.Run("test.rsc", strings.NewReader(script)))
+	t.Require().Equal("zero\n1\n2\n3\n2\n1\n1\n9\n25\n7\nfive\n0\n", t.out.String())
+}
+
+func (t *runnerTestSuite) TestLocals() {
+	script := `
+:local a 5
+:local b ($a * 2)
+:put ($a + $b)
+{ :local a 1; :put $a }
+:put $a
+`
+	t.Require().NoError(t.runner.Run("test.rsc", strings.NewReader(script)))
+	t.Require().Equal("15\n1\n5\n", t.out.String())
+
+	// a script doesn't see the locals of the one run before it
+	t.out.Reset()
+	t.Require().NoError(t.runner.RunString(`:put $a`))
+	t.Require().Equal("", t.out.String())
+}
+
+func (t *runnerTestSuite) TestErrors() {
+	err := t.runner.Run("test.rsc", strings.NewReader(":put 1\n\n:put 2 3\n:put 4\n"))
+	t.Require().Error(err)
+	t.Require().True(errors.Is(err, ErrSyntax))
+
+	var scriptErr *Error
+	t.Require().True(errors.As(err, &scriptErr))
+	t.Require().Equal("test.rsc", scriptErr.Name)
+	t.Require().Equal(3, scriptErr.Line)
+	t.Require().Equal("1\n", t.out.String())
+
+	err = t.runner.Run("test.rsc", strings.NewReader(":put 1\n{\n:put 2\n"))
+	t.Require().True(errors.Is(err, ErrUnclosed))
+	t.Require().True(errors.As(err, &scriptErr))
+	t.Require().Equal(3, scriptErr.Line)
+}
+
+func (t *runnerTestSuite) TestSyntaxErrors() {
+	err := t.runner.Run("test.rsc", strings.NewReader(":put 1\n{\n  :put 2 x=3\n}\n"))
+	t.Require().True(errors.Is(err, ErrSyntax))
+
+	var scriptErr *Error
+	t.Require().True(errors.As(err, &scriptErr))
+	t.Require().Equal(3, scriptErr.Line)
+	t.Require().Equal(10, scriptErr.Column)
+	t.Require().Contains(err.Error(), "expected end of command")
+}
+
+func (t *runnerTestSuite) TestDelay() {
+	t.Require().NoError(t.runner.RunString(`:put 1; :delay 1ms; :put 2`))
+	t.Require().Equal("1\n2\n", t.out.String())
+
+	err := t.runner.RunString(`:delay "soon"`)
+	t.Require().True(errors.Is(err, ErrRuntime))
+	t.Require().Contains(err.Error(), ":delay: wrong type")
+}
+
+func (t *runnerTestSuite) TestJobs() {
+	script := `
+:global done false
+:execute {:put "background"; :global done true}
+:put "foreground"
+:while ($done = false) do={ :delay 1ms }
+:put $done
+`
+	t.Require().NoError(t.runner.Run("test.rsc", strings.NewReader(script)))
+	t.Require().Equal("foreground\ntrue\n", t.out.String())
+
+	t.out.Reset()
+
+	t.Require().NoError(t.runner.RunString(`:global id ([:execute script={ :delay 1h }]); /system script job print`))
+	t.Require().Contains(t.out.String(), "SOURCE")
+	t.Require().Contains(t.out.String(), "{ :delay 1h }")
+
+	t.out.Reset()
+
+	t.Require().NoError(t.runner.RunString(`/system script job remove $id; /system script job print`))
+	t.Require().NotContains(t.out.String(), ":delay")
+
+	err := t.runner.RunString(`/system script job remove $id`)
+	t.Require().True(errors.Is(err, ErrRuntime))
+	t.Require().Contains(err.Error(), "no such item: ")
+
+	err = t.runner.RunString(`:execute ":put 1"`)
+	t.Require().True(errors.Is(err, ErrRuntime))
+}
+
+func (t *runnerTestSuite) TestStoredScripts() {
+	script := `
+/system script add name=greet source={ :put ("hello " . $who) }
+/system script add name=twice source=":global who world; /system script run greet; /system script run 0"
+/system script run twice
+:put [/system script run greet]
+/system script print
+`
+	t.Require().NoError(t.runner.Run("test.rsc", strings.NewReader(script)))
+	t.Require().Contains(t.out.String(), "hello world\nhello world\nhello world\n")
+	t.Require().Regexp(`0    greet .* 3 +:put \("hello " \. \$who\)\n`, t.out.String())
+	t.Require().Regexp(`1    twice .* 1 +:global who world`, t.out.String())
+
+	t.out.Reset()
+	t.Require().NoError(t.runner.RunString(`/system script print terse`))
+	t.Require().Regexp(`^  0    name=greet last-run="[^"]+" run-count=3 source=":put \(\\"hello \\" \. \$who\)"\n`, t.out.String())
+
+	err := t.runner.RunString(`/system script print detail terse`)
+	t.Require().Contains(err.Error(), "detail and terse can't be used together")
+
+	err = t.runner.RunString(`/system script add name=loop source={ /system script run loop }; /system script run loop`)
+	t.Require().True(errors.Is(err, ErrRuntime))
+	t.Require().Contains(err.Error(), "command:1:66: runtime error: /system script run: scripts nested deeper than 16")
+
+	err = t.runner.RunString(`/system script add name=fail source={ :put (1 / 0) }; :put 1; /system script run fail`)
+	t.Require().True(errors.Is(err, ErrRuntime))
+	t.Require().Contains(err.Error(), "command:1:63: runtime error: /system script run: script fail: :put: division by zero")
+
+	err = t.runner.RunString(`:do { /system script run fail } on-error={ :put $error }`)
+	t.Require().NoError(err)
+
+	err = t.runner.RunString(`/system script add name=bad source=":put ("`)
+	t.Require().True(errors.Is(err, ErrRuntime))
+	t.Require().Contains(err.Error(), "/system script add: script bad: syntax error: unclosed `(`")
+
+	err = t.runner.RunString(`/system script add name=a source="b"`)
+	t.Require().Contains(err.Error(), "script a: syntax error: unknown command `b`")
+
+	err = t.runner.RunString(`/system script add name=greet source=":put 1"`)
+	t.Require().Contains(err.Error(), "script already exists: greet")
+
+	t.Require().NoError(t.runner.RunString(`/system script remove greet`))
+
+	err = t.runner.RunString(`/system script run greet`)
+	t.Require().Contains(err.Error(), "no such script: greet")
+}
+
+func (t *runnerTestSuite) TestScheduler() {
+	script := `
+:global runs 0
+/system scheduler add name=tick interval=10ms on-event={ :global runs ($runs + 1) }
+:while ($runs < 2) do={ :delay 1ms }
+/system scheduler disable tick
+/system scheduler print
+`
+	t.Require().NoError(t.runner.Run("test.rsc", strings.NewReader(script)))
+	t.Require().Contains(t.out.String(), "NEXT-RUN")
+	t.Require().Regexp(`0 X  tick +00:00:00\.010`, t.out.String())
+	t.Require().Contains(t.out.String(), "{ :global runs ($runs + 1) }")
+
+	t.out.Reset()
+
+	t.Require().NoError(t.runner.RunString(`/system scheduler add name=fail start-time=00:00:00 interval=1d on-event={ :error "boom" } disabled=yes; /system scheduler remove 0; /system scheduler print`))
+	t.Require().NotContains(t.out.String(), "tick")
+	t.Require().Regexp(`0 X  fail`, t.out.String())
+
+	t.out.Reset()
+	t.Require().NoError(t.runner.RunString(`/system scheduler print detail`))
+	t.Require().Regexp(`^Flags: X - disabled\n  0 X  name=fail interval=1d00:00:00 next-run="[^"]+" run-count=0 on-event="\{ :error \\"boom\\" \}"`, t.out.String())
+
+	err := t.runner.RunString(`/system scheduler remove tick`)
+	t.Require().True(errors.Is(err, ErrRuntime))
+	t.Require().Contains(err.Error(), "no such task: tick")
+
+	err = t.runner.RunString(`/system scheduler add name=fail interval=1d on-event={ :put 1 }`)
+	t.Require().Contains(err.Error(), "task already exists: fail")
+
+	err = t.runner.RunString(`/system scheduler add name=never on-event={ :put 1 }`)
+	t.Require().Contains(err.Error(), "interval or start-time is required")
+
+	err = t.runner.RunString(`/system scheduler add name=str interval=1s on-event=":put 1"`)
+	t.Require().True(errors.Is(err, ErrRuntime))
+}
+
+func (t *runnerTestSuite) TestRuntimeErrors() {
+	script := `
+:put 1
+:local a 5; :put ($a / 0)
+:put 3
+`
+	err := t.runner.Run("test.rsc", strings.NewReader(script))
+	t.Require().True(errors.Is(err, ErrRuntime))
+
+	var scriptErr *Error
+	t.Require().True(errors.As(err, &scriptErr))
+	t.Require().Equal(3, scriptErr.Line)
+	t.Require().Equal(18, scriptErr.Column)
+	t.Require().Contains(err.Error(), ":put: division by zero")
+	t.Require().Equal("1\n", t.out.String())
+
+	t.out.Reset()
+
+	err = t.runner.RunString(`:for i from=1 to=3 do={ :put $i; :put (10 / (2 - $i)) }; :put done`)
+	t.Require().True(errors.Is(err, ErrRuntime))
+	t.Require().Equal("1\n10\n2\n", t.out.String())
+}
+
+func (t *runnerTestSuite) TestErrorHandling() {
+	script := `
+:do { :put 1; :error "boom"; :put 2 } on-error={ :put ("caught " . $error) }
+:do { :put (1 / 0) } on-error={ :put $error }
+:do { :do { :error "inner" } } on-error={ :put $error }
+:do { :put ok } on-error={ :put unused }
+:for i from=-1 to=1 do={ :do { :put (6 / $i) } on-error={ :put skipped } }
+`
+	t.Require().NoError(t.runner.Run("test.rsc", strings.NewReader(script)))
+	t.Require().Equal("1\ncaught boom\n:put: division by zero\ninner\nok\n-6\nskipped\n6\n", t.out.String())
+
+	t.out.Reset()
+
+	err := t.runner.RunString(`:put 1; :error ("code " . 5); :put 2`)
+	t.Require().True(errors.Is(err, ErrRuntime))
+	t.Require().Contains(err.Error(), "runtime error: code 5")
+	t.Require().Equal("1\n", t.out.String())
+}
+
+func (t *runnerTestSuite) TestArrays() {
+	script := `
+:global a {1;2;{3;4}}
+:global m {a=1;b="two"}
+:global f {:put "block"}
+:put $a
+:put ($a->1)
+:put ($a->2->0)
+:put ({5;6}->1)
+:put ($m->"b")
+:put $m
+:put [:len $a]
+:put [:len "hello"]
+:put [:pick $a 0 2]
+:put [:pick "hello" 1 3]
+:put [:find $a 2]
+:foreach x in={7;8} do={ :put $x }
+$f
+`
+	t.Require().NoError(t.runner.Run("test.rsc", strings.NewReader(script)))
+	t.Require().Equal(
+		"1;2;{3;4}\n2\n3\n6\ntwo\na=1;b=two\n3\n5\n1;2\nel\n1\n7\n8\nblock\n",
+		t.out.String(),
+	)
+}
+
+func (t *runnerTestSuite) TestNetworkValues() {
+	script := `
+:global gw 10.0.0.1
+:global net 10.0.0.0/30
+:put ($gw + 1)
+:foreach a in={10.0.0.3;10.0.0.4} do={
+	:if ($a in $net) do={ :put ($a . " in") } else={ :put ($a . " out") }
+}
+:put (2001:db8::1 in 2001:db8::/32)
+:global t 1h30m
+:put ($t - 45m)
+`
+	t.Require().NoError(t.runner.Run("test.rsc", strings.NewReader(script)))
+	t.Require().Equal("10.0.0.2\n10.0.0.3 in\n10.0.0.4 out\ntrue\n00:45:00\n", t.out.String())
+}
+
+func (t *runnerTestSuite) TestConversions() {
+	script := `
+:put [:typeof 5]
+:put [:typeof "5"]
+:put [:typeof $missing]
+:put [:typeof {1;2}]
+:put [:typeof 10.0.0.0/8]
+:put [:typeof [:find "abc" "z"]]
+:put ("5" = 5)
+:put ([:tonum "5"] = 5)
+:put [:typeof [:tostr 5]]
+:put [:tonum 1m]
+:put [:typeof [:tonum "five"]]
+:put [:tobool 2]
+:put [:typeof [:tobool "maybe"]]
+:put ([:toarray "1, 2,10.0.0.1"]->2 + 1)
+:put [:len [:toarray ""]]
+:put [:typeof "10.0.0.1"]
+:put [:typeof 10.0.0.1]
+`
+	t.Require().NoError(t.runner.Run("test.rsc", strings.NewReader(script)))
+	t.Require().Equal(
+		"num\nstr\nnothing\narray\nip-prefix\nnil\nfalse\ntrue\nstr\n60\nnil\ntrue\nnil\n10.0.0.2\n1\nstr\nip\n",
+		t.out.String(),
+	)
+}
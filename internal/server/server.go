@@ -0,0 +1,158 @@
+package server
+
+import (
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/blkmlk/microshell/internal/terminal"
+)
+
+var ErrServerClosed = errors.New("server closed")
+
+// Session is run on a connection until the client leaves.
+type Session interface {
+	Run()
+}
+
+// SessionFactory creates the session served on a new connection.
+type SessionFactory func(term terminal.Terminal) (Session, error)
+
+// Server accepts telnet connections and runs a separate session with its own
+// terminal on each of them.
+type Server struct {
+	factory SessionFactory
+
+	mu        sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+func New(factory SessionFactory) *Server {
+	return &Server{
+		factory:   factory,
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}
+}
+
+// ListenAndServe listens on the TCP address addr and serves the connections.
+func (s *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return s.Serve(l)
+}
+
+// Serve accepts connections on l until the server is closed.
+func (s *Server) Serve(l net.Listener) error {
+	if !s.track(l, true) {
+		return ErrServerClosed
+	}
+
+	defer s.track(l, false)
+
+	for {
+		conn, err := l.Accept()
+
+		if err != nil {
+			if s.isClosed() {
+				return ErrServerClosed
+			}
+
+			return err
+		}
+
+		if !s.trackConn(conn, true) {
+			conn.Close()
+			return ErrServerClosed
+		}
+
+		go s.serve(conn)
+	}
+}
+
+// Close stops the listeners, closes the connections and waits for the
+// sessions to finish.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+
+	var err error
+	for l := range s.listeners {
+		if closeErr := l.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}
+
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+
+	return err
+}
+
+func (s *Server) serve(conn net.Conn) {
+	defer s.wg.Done()
+	defer s.trackConn(conn, false)
+	defer conn.Close()
+
+	term := terminal.NewTelnetTerminal(conn)
+
+	session, err := s.factory(term)
+	if err != nil {
+		term.WriteToConsole(err.Error() + "\n")
+		return
+	}
+
+	session.Run()
+}
+
+func (s *Server) track(l net.Listener, add bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if add {
+		if s.closed {
+			return false
+		}
+
+		s.listeners[l] = struct{}{}
+	} else {
+		delete(s.listeners, l)
+	}
+
+	return true
+}
+
+func (s *Server) trackConn(conn net.Conn, add bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if add {
+		if s.closed {
+			return false
+		}
+
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+	} else {
+		delete(s.conns, conn)
+	}
+
+	return true
+}
+
+func (s *Server) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.closed
+}
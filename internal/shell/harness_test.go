@@ -0,0 +1,167 @@
+package shell
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/blkmlk/microshell/internal/cursor"
+	"github.com/blkmlk/microshell/internal/history"
+	"github.com/blkmlk/microshell/internal/logger"
+	"github.com/blkmlk/microshell/internal/parser"
+	"github.com/blkmlk/microshell/internal/prompt"
+	"github.com/blkmlk/microshell/internal/terminal"
+	"github.com/sarulabs/di/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// virtualTerminal is the screen the harness draws on.
+type virtualTerminal interface {
+	terminal.Terminal
+	Line(y int) string
+	Screen() []string
+	Cell(x, y int) terminal.Cell
+	Cursor() (x, y int)
+	Resize(width, height int)
+	Resized() <-chan struct{}
+}
+
+type nopLogger struct{}
+
+func (nopLogger) WriteMessages(...interface{}) {}
+
+// harness drives a shell key by key on a virtual terminal.
+type harness struct {
+	t        *testing.T
+	shell    *Shell
+	terminal virtualTerminal
+	closed   bool
+}
+
+func newHarness(t *testing.T, width, height int, commands ...*parser.Command) *harness {
+	h := &harness{
+		t:        t,
+		terminal: terminal.NewVirtualTerminal(width, height),
+	}
+
+	builder, err := di.NewBuilder()
+	require.NoError(t, err)
+
+	err = builder.Add(
+		di.Def{
+			Name: terminal.DefinitionName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				return h.terminal, nil
+			},
+		},
+		di.Def{
+			Name: logger.DefinitionName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				return nopLogger{}, nil
+			},
+		},
+		cursor.Definition,
+		prompt.Definition,
+		history.Definition,
+		terminal.DefinitionBuffer,
+		parser.Definition,
+		parser.DefinitionScope,
+		parser.DefinitionContext,
+		parser.DefinitionClock,
+		di.Def{
+			Name: parser.DefinitionNameCommandTree,
+			Build: func(ctn di.Container) (interface{}, error) {
+				return parser.List{Commands: commands}, nil
+			},
+		},
+	)
+	require.NoError(t, err)
+
+	h.shell = NewShell(builder.Build())
+	h.shell.SetColors(map[parser.Object]terminal.Color{
+		parser.ObjectPath:    terminal.ColorBlue,
+		parser.ObjectCommand: terminal.ColorBlue,
+		parser.ObjectError:   terminal.ColorRed,
+	})
+	h.shell.cancel = func() {
+		h.closed = true
+	}
+	h.shell.render(RenderTypeFull, 0)
+
+	return h
+}
+
+// Type presses a key for every rune of text, an entered command is waited
+// for.
+func (h *harness) Type(text string) {
+	for _, r := range text {
+		h.read(string(r))
+		h.Wait()
+	}
+}
+
+// Press presses the keys, a key may be an escape sequence or the runes of a
+// single read, e.g. pasted text.
+func (h *harness) Press(keys ...string) {
+	for _, key := range keys {
+		h.read(key)
+		h.Wait()
+	}
+}
+
+// Start types text and enters it without waiting for the command.
+func (h *harness) Start(text string) {
+	for _, r := range text + "\r" {
+		h.read(string(r))
+	}
+}
+
+// read handles the keys of the runes read from the terminal at once.
+func (h *harness) read(rs string) {
+	for _, key := range decodeKeys([]rune(rs)) {
+		h.shell.handleKey(key)
+	}
+}
+
+// Wait waits for the running command and draws its output.
+func (h *harness) Wait() {
+	if !h.shell.running {
+		return
+	}
+
+	<-h.shell.executed
+	h.shell.finish()
+}
+
+// Resize changes the size of the terminal and waits for the shell to draw
+// the input again.
+func (h *harness) Resize(width, height int) {
+	h.terminal.Resize(width, height)
+	<-h.terminal.Resized()
+	h.shell.resize()
+}
+
+// Line returns the text of the row y counted from 1.
+func (h *harness) Line(y int) string {
+	return h.terminal.Line(y)
+}
+
+func (h *harness) RequireLine(y int, expected string) {
+	require.Equal(h.t, expected, h.Line(y), "screen:\n%s", h.dump())
+}
+
+// RequireCursor checks the cursor is at the column x and the row y counted
+// from 1.
+func (h *harness) RequireCursor(x, y int) {
+	actualX, actualY := h.terminal.Cursor()
+	require.Equal(h.t, []int{x, y}, []int{actualX, actualY}, "screen:\n%s", h.dump())
+}
+
+func (h *harness) dump() string {
+	var out strings.Builder
+	for i, line := range h.terminal.Screen() {
+		fmt.Fprintf(&out, "%2d|%s\n", i+1, line)
+	}
+
+	return out.String()
+}
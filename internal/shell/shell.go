@@ -2,9 +2,13 @@ package shell
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"io"
+	"runtime/debug"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/blkmlk/microshell/internal/parser"
 
@@ -18,6 +22,7 @@ import (
 
 	"github.com/blkmlk/microshell/internal/prompt"
 
+	"github.com/mattn/go-runewidth"
 	"github.com/sarulabs/di/v2"
 
 	"github.com/blkmlk/microshell/internal/terminal"
@@ -30,6 +35,7 @@ const (
 	KeyCtrlD     = 4
 	KeyCtrlE     = 5
 	KeyCtrlF     = 6
+	KeyCtrlG     = 7
 	KeyCtrlH     = 8
 	KeyBackspace = 0x7F
 	KeyTab       = 9
@@ -38,6 +44,7 @@ const (
 	KeyEnter     = 13
 	KeyCtrlN     = 14
 	KeyCtrlP     = 16
+	KeyCtrlR     = 18
 	KeyCtrlT     = 20
 	KeyCtrlU     = 21
 	KeyCtrlW     = 23
@@ -50,6 +57,8 @@ const (
 	KeyF         = 102
 	KeyAltB      = -1
 	KeyAltF      = -2
+	KeyArrowUp   = -3
+	KeyArrowDown = -4
 	KeySuggest   = 1000
 )
 
@@ -77,6 +86,7 @@ type Shell struct {
 	history  history.History
 	prompt   prompt.Prompt
 	parser   parser.Parser
+	scope    parser.SystemContext
 	logger   logger.Logger
 	buffer   terminal.Buffer
 
@@ -92,7 +102,25 @@ type Shell struct {
 	usedLines    int
 	promptOffset int
 	oldPrompt    string
+	secret       bool
+	search       *search
 	cancel       context.CancelFunc
+	// syntaxErr is the error of the last parsed input
+	syntaxErr error
+	// running is set while the entered command executes, executed receives
+	// when it is done. It is buffered as nobody waits for the command once
+	// the shell has stopped.
+	running  bool
+	executed chan struct{}
+}
+
+// search is the state of the reverse incremental history search.
+type search struct {
+	query  []rune
+	failed bool
+	// index and position restore the line the search has started at
+	index    int
+	position int
 }
 
 func NewShell(ctn di.Container) *Shell {
@@ -101,18 +129,19 @@ func NewShell(ctn di.Container) *Shell {
 		history:       ctn.Get(history.DefinitionName).(history.History),
 		terminal:      ctn.Get(terminal.DefinitionName).(terminal.Terminal),
 		parser:        ctn.Get(parser.DefinitionName).(parser.Parser),
+		scope:         ctn.Get(parser.DefinitionNameRootScope).(parser.SystemContext),
 		logger:        ctn.Get(logger.DefinitionName).(logger.Logger),
 		buffer:        ctn.Get(terminal.DefinitionNameBuffer).(terminal.Buffer),
 		usernameColor: terminal.ColorBlue,
 		hostnameColor: terminal.ColorGreen,
 		ticker:        make(chan bool),
+		executed:      make(chan struct{}, 1),
 		colors:        make(map[parser.Object]terminal.Color),
 		lines:         1,
 		usedLines:     1,
 	}
 
-	shell.prompt.SetHostname("localhost")
-	shell.prompt.SetUsername("void")
+	shell.SetPrompt("localhost", "void")
 
 	return shell
 }
@@ -133,6 +162,13 @@ func (s *Shell) SetColors(colors map[parser.Object]terminal.Color) {
 	s.colors = colors
 }
 
+// SetPrompt also makes the user the owner of the jobs started in the shell.
+func (s *Shell) SetPrompt(hostname, username string) {
+	s.prompt.SetHostname(hostname)
+	s.prompt.SetUsername(username)
+	s.scope.WithUser(username)
+}
+
 func (s *Shell) render(rType RenderType, offset int) {
 	s.logger.WriteMessages(s.getCursor().String(), "--", s.getCursor().StringFromPosition())
 
@@ -179,6 +215,8 @@ func (s *Shell) parse(pType ParseType) error {
 			err = resp.Error
 		}
 
+		s.syntaxErr = resp.Error
+		s.secret = resp.Secret
 		s.colorText(resp.Objects)
 		s.updateCursorLocation(0)
 
@@ -277,7 +315,7 @@ func (s *Shell) currentXOffset(position int) int {
 		position = -1
 	}
 
-	return (position + 1 + s.promptOffset) % s.terminal.Width()
+	return s.cellOffset(position+1) % s.terminal.Width()
 }
 
 func (s *Shell) currentYOffset(position int) int {
@@ -286,30 +324,86 @@ func (s *Shell) currentYOffset(position int) int {
 		position = -1
 	}
 
-	return (s.terminal.Height() - s.lines) + (position+1+s.promptOffset)/s.terminal.Width()
+	return (s.terminal.Height() - s.lines) + s.cellOffset(position+1)/s.terminal.Width()
+}
+
+// cellOffset returns the number of the screen cells taken by the prompt and
+// the first n runes of the input. A wide rune which doesn't fit at the end of
+// a line is moved to the next one by the terminal leaving the last cell empty.
+func (s *Shell) cellOffset(n int) int {
+	width := s.terminal.Width()
+	runes := []rune(s.getCursor().String())
+	offset := s.promptOffset
+
+	for i := 0; i < n; i++ {
+		w := 1
+		if i < len(runes) {
+			w = runewidth.RuneWidth(runes[i])
+		}
+
+		if w > 1 && offset%width+w > width {
+			offset += width - offset%width
+		}
+
+		offset += w
+	}
+
+	return offset
 }
 
 func (s *Shell) printPrompt() {
 	var offset int
-	s.terminal.SetColor(terminal.ColorWhite)
-	offset += s.terminal.WriteToConsole("[")
+	for _, w := range s.promptWords() {
+		s.terminal.SetColor(w.Color())
+		offset += s.writeCells(w.Text())
+	}
 
-	s.terminal.SetColor(s.hostnameColor)
-	offset += s.terminal.WriteToConsole(s.prompt.Hostname())
+	s.promptOffset = offset
+}
 
-	if s.prompt.Username() != "" {
-		s.terminal.SetColor(terminal.ColorWhite)
-		offset += s.terminal.WriteToConsole("@")
+// promptWidth returns the number of the screen cells taken by the prompt.
+func (s *Shell) promptWidth() int {
+	var width int
+	for _, w := range s.promptWords() {
+		width += w.Len()
+	}
 
-		s.terminal.SetColor(s.usernameColor)
-		offset += s.terminal.WriteToConsole(s.prompt.Username())
+	return width
+}
+
+// promptWords returns the prompt, the search prompt while searching the
+// history.
+func (s *Shell) promptWords() []terminal.Word {
+	if s.search != nil {
+		text := "(reverse-i-search)'" + string(s.search.query) + "':"
+		if s.search.failed {
+			text = "(failed " + text[1:]
+		}
+
+		return []terminal.Word{terminal.NewWord(text, terminal.ColorWhite)}
 	}
 
-	s.terminal.SetColor(terminal.ColorWhite)
-	offset += s.terminal.WriteToConsole("]")
-	offset += s.terminal.WriteToConsole(" " + s.prompt.StartChar())
+	words := []terminal.Word{
+		terminal.NewWord("[", terminal.ColorWhite),
+		terminal.NewWord(s.prompt.Hostname(), s.hostnameColor),
+	}
 
-	s.promptOffset = offset
+	if s.prompt.Username() != "" {
+		words = append(words,
+			terminal.NewWord("@", terminal.ColorWhite),
+			terminal.NewWord(s.prompt.Username(), s.usernameColor),
+		)
+	}
+
+	return append(words,
+		terminal.NewWord("] "+s.prompt.StartChar(), terminal.ColorWhite),
+	)
+}
+
+// writeCells writes s and returns the number of the screen cells it takes.
+func (s *Shell) writeCells(text string) int {
+	s.terminal.WriteToConsole(text)
+	return runewidth.StringWidth(text)
 }
 
 func (s *Shell) printText(full bool, renderOffset int) {
@@ -327,35 +421,21 @@ func (s *Shell) printText(full bool, renderOffset int) {
 
 	s.logger.WriteMessages("text:", text, "pos:", s.getCursor().Position())
 
-	lines := 0
-	for text != "" {
-		width := s.terminal.Width()
-		offset := s.currentXOffset(s.getCursor().Position())
-		newLine := false
-
-		s.logger.WriteMessages(offset, width)
-
-		if offset == 0 && s.currentYOffset(s.getCursor().Len()) == s.terminal.Height() {
-			newLine = true
-		}
-
-		width -= offset
+	s.terminal.WriteToConsole(text)
 
-		var t string
-		if len(text) <= width || width == 0 {
-			t = text
-			text = ""
-		} else {
-			t = text[:width]
-			text = text[width:]
-		}
+	width := s.terminal.Width()
+	end := s.cellOffset(s.getCursor().Len())
 
-		s.terminal.WriteToConsole(t)
+	// the terminal scrolls the screen when the text goes below the last line
+	lines := 0
+	if used := (end-1)/width + 1; used > s.lines {
+		lines = used - s.lines
+	}
 
-		if newLine {
-			s.terminal.WriteToConsole("\n")
-			lines++
-		}
+	// the cursor behind the last cell of the bottom line needs a new line
+	if end%width == 0 && end/width+1 > s.lines+lines {
+		s.terminal.WriteToConsole("\n")
+		lines++
 	}
 
 	s.lines += lines
@@ -366,16 +446,52 @@ func (s *Shell) printText(full bool, renderOffset int) {
 func (s *Shell) enter() {
 	s.buffer.Push(terminal.NewPlainText("\n"))
 	l := s.buffer.Len()
+
+	if s.syntaxErr != nil {
+		s.pushSyntaxError(s.syntaxErr)
+		s.buffer.Push(terminal.NewPlainText("\n"))
+		return
+	}
+
 	resp, err := s.parser.Exec()
 
-	if err == nil && resp.Value != nil {
+	switch {
+	case err != nil:
+		if s.buffer.Len() > l {
+			s.buffer.Push(terminal.NewPlainText("\n"))
+		}
+
+		message := err.Error()
+		if errors.Is(err, context.Canceled) {
+			message = "interrupted"
+		}
+
+		s.buffer.Push(terminal.NewColoredText(message, s.getColor(parser.ObjectError)))
+	case resp.Error != nil:
+		s.pushSyntaxError(resp.Error)
+	case resp.Value != nil:
 		s.logger.WriteMessages("Resp:", resp.Value.String())
 	}
+
 	if s.buffer.Len() > l {
 		s.buffer.Push(terminal.NewPlainText("\n"))
 	}
 }
 
+// pushSyntaxError marks the position of the error under the input line. The
+// input starts with a space, so the offset is the column of the error.
+func (s *Shell) pushSyntaxError(err error) {
+	offset := parser.SyntaxOffset(err, s.getCursor().Len())
+	marker := strings.Repeat(" ", s.cellOffset(offset)%s.terminal.Width()) + "^\n"
+
+	s.buffer.Push(terminal.NewPlainText(marker))
+	s.buffer.Push(terminal.NewColoredText(syntaxMessage(err, offset), s.getColor(parser.ObjectError)))
+}
+
+func syntaxMessage(err error, column int) string {
+	return fmt.Sprintf("syntax error: %v (line 1 column %d)", err, column)
+}
+
 func (s *Shell) ReadRunes(ctx context.Context) chan models.Rune {
 	ch := make(chan models.Rune, 5)
 	go func() {
@@ -383,208 +499,473 @@ func (s *Shell) ReadRunes(ctx context.Context) chan models.Rune {
 			rs, err := s.terminal.ReadRunes()
 
 			if err != nil {
-				log.Fatal(err)
-			}
-
-			var r models.Rune
-
-			if len(rs) == 3 {
-				switch rs[2] {
-				case KeyUp:
-					r = KeyCtrlP
-				case KeyDown:
-					r = KeyCtrlN
-				case KeyRight:
-					r = KeyCtrlF
-				case KeyLeft:
-					r = KeyCtrlB
-				}
-			} else if len(rs) == 2 && rs[0] == KeyEsc {
-				switch rs[1] {
-				case KeyB:
-					r = KeyAltB
-				case KeyF:
-					r = KeyAltF
+				if err != io.EOF {
+					s.logger.WriteMessages("ReadRunes:", err.Error())
 				}
-			} else {
-				r = models.Rune(rs[0])
+				s.cancel()
+				return
 			}
 
-			select {
-			case <-ctx.Done():
-				return
-			case ch <- r:
+			for _, r := range decodeKeys(rs) {
+				select {
+				case <-ctx.Done():
+					return
+				case ch <- r:
+				}
 			}
 		}
 	}()
 	return ch
 }
 
+// decodeKeys maps the runes of a read to the keys. A read starting with ESC
+// is the escape sequence of a single key, any other is the runes typed or
+// pasted since the last read.
+func decodeKeys(rs []rune) []models.Rune {
+	if len(rs) > 1 && rs[0] == KeyEsc {
+		return []models.Rune{decodeEscape(rs)}
+	}
+
+	keys := make([]models.Rune, 0, len(rs))
+	for _, r := range rs {
+		keys = append(keys, models.Rune(r))
+	}
+
+	return keys
+}
+
+// decodeEscape maps an escape sequence to the key, 0 if it is not known.
+func decodeEscape(rs []rune) models.Rune {
+	if len(rs) == 3 {
+		switch rs[2] {
+		case KeyUp:
+			return KeyArrowUp
+		case KeyDown:
+			return KeyArrowDown
+		case KeyRight:
+			return KeyCtrlF
+		case KeyLeft:
+			return KeyCtrlB
+		}
+		return 0
+	} else if len(rs) == 2 {
+		switch rs[1] {
+		case KeyB:
+			return KeyAltB
+		case KeyF:
+			return KeyAltF
+		}
+	}
+
+	return 0
+}
+
 func (s *Shell) Run() {
 	defer s.terminal.ResetTerminal()
 
-	renderType := RenderTypeFull
-	renderOffset := 0
-	s.render(renderType, renderOffset)
+	if reader, ok := s.terminal.(terminal.LineReader); ok {
+		s.runLines(reader)
+		return
+	}
+
+	s.render(RenderTypeFull, 0)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	s.cancel = cancel
 
-	var completeCh = make(chan models.Rune)
 	ch := s.ReadRunes(ctx)
 
-	var r models.Rune
+	var resized <-chan struct{}
+	if r, ok := s.terminal.(terminal.Resizer); ok {
+		resized = r.Resized()
+	}
 
 	for {
-		s.logger.WriteMessages("RenderType: ", renderType)
-		renderOffset = 0
-
 		select {
 		case <-ctx.Done():
+			// the client has gone, the command has no one to run for
+			if s.running {
+				s.parser.Cancel()
+			}
+
 			return
-		case r = <-ch:
-		case r = <-completeCh:
+		case r := <-ch:
+			s.handleKey(r)
+		case <-s.executed:
+			s.finish()
+		case <-resized:
+			s.resize()
 		}
+	}
+}
 
-		switch r {
-		case KeyTab:
-			if s.getCursor().Position() != s.getCursor().Len()-1 {
-				continue
-			}
+// resize draws the input again for the new size of the terminal. While a
+// command runs there is no input and redrawing it would parse it again,
+// replacing the context the command is cancelled by; finish draws it.
+func (s *Shell) resize() {
+	if s.running {
+		return
+	}
 
-			resp := s.parser.Continue()
+	s.redraw()
+}
 
-			if resp == nil {
-				continue
-			}
+// redraw draws the prompt and the input again after the terminal has changed
+// its size or the input has been replaced. The input stays at the bottom of
+// the screen.
+func (s *Shell) redraw() {
+	position := s.getCursor().Position()
+	s.promptOffset = s.promptWidth()
+
+	lines := s.cellOffset(s.getCursor().Len())/s.terminal.Width() + 1
+	if lines > s.lines {
+		// scroll the output up to make room for the wrapped input
+		s.terminal.MoveCursorToPosition(0, s.terminal.Height())
+		s.terminal.WriteToConsole(strings.Repeat("\n", lines-s.lines))
+	} else {
+		s.clearSpace()
+	}
 
-			completeCh = make(chan models.Rune, len(resp.Merged))
-			for _, c := range resp.Merged {
-				completeCh <- models.Rune(c)
-			}
+	s.lines = lines
+	s.usedLines = lines
 
-			if resp.Merged == "" && len(resp.Options) > 0 {
-				s.buffer.Push(terminal.NewPlainText("\n"))
-
-				out := terminal.NewFlexibleTable()
-				var opts []string
-				for _, opt := range resp.Options {
-					opts = append(opts, opt.Option)
-					w := terminal.Word{}
-					switch opt.Level {
-					case parser.LevelTypePath:
-						color := s.colors[parser.ObjectPath]
-						w.SetColor(color)
-					case parser.LevelTypeCommand:
-						color := s.colors[parser.ObjectCommand]
-						w.SetColor(color)
-					case parser.LevelTypeFlag:
-						color := s.colors[parser.ObjectMandatoryFlag]
-						w.SetColor(color)
-					case parser.LevelTypeOption:
-						color := s.colors[parser.ObjectOption]
-						w.SetColor(color)
-					default:
-						w.SetColor(terminal.ColorWhite)
-					}
-					w.SetText(opt.Option)
-					out.AddWord(w)
-				}
-				s.buffer.Push(out)
-				s.buffer.Push(terminal.NewPlainText("\n"))
-
-				s.logger.WriteMessages("Options: ", strings.Join(opts, ","))
-				s.logger.WriteMessages("Merged: ", resp.Merged)
-				completeCh = make(chan models.Rune, 1)
-				completeCh <- KeySuggest
-				renderType = RenderTypeFullTrim
-			}
+	s.render(RenderTypeFull, 0)
+	s.parse(ParseTypeFull)
 
-			continue
-		case KeySuggest:
-			s.printBuffer()
-		case KeyCtrlF:
-			s.getCursor().MoveForward()
-			renderType = RenderTypeCursorOnly
-		case KeyCtrlA:
-			s.getCursor().MoveToStart()
-			renderType = RenderTypeCursorOnly
-		case KeyCtrlB:
-			s.getCursor().MoveBackward()
-			renderType = RenderTypeCursorOnly
-		case KeyCtrlD:
-			if s.getCursor().Position() == 0 && s.getCursor().String() == " " {
-				s.cancel()
-				continue
-			}
+	s.getCursor().SetPosition(position)
+	s.updateCursorLocation(0)
+}
 
-			s.getCursor().Delete()
-			renderType = RenderTypePartialClear
-		case KeyCtrlE:
-			s.getCursor().MoveToEnd()
-			renderType = RenderTypeCursorOnly
-		case KeyCtrlH, KeyBackspace:
-			s.getCursor().Backspace()
-			renderType = RenderTypePartialClear
-			renderOffset = -1
-		case KeyCtrlU:
-			s.getCursor().DeleteToStart()
-			renderType = RenderTypePartialClear
-		case KeyEnter:
+// handleKey edits the input line and renders it.
+func (s *Shell) handleKey(r models.Rune) {
+	if s.running {
+		// the keys wait for the command except Ctrl-C interrupting it
+		if r == KeyCtrlC {
+			s.parser.Cancel()
+		}
+
+		return
+	}
+
+	if s.search != nil && s.handleSearchKey(r) {
+		return
+	}
+
+	renderType := RenderTypeSkip
+	renderOffset := 0
+
+	switch r {
+	case KeyTab:
+		s.complete()
+		return
+	case KeyCtrlF:
+		s.getCursor().MoveForward()
+		renderType = RenderTypeCursorOnly
+	case KeyCtrlA:
+		s.getCursor().MoveToStart()
+		renderType = RenderTypeCursorOnly
+	case KeyCtrlB:
+		s.getCursor().MoveBackward()
+		renderType = RenderTypeCursorOnly
+	case KeyCtrlD:
+		if s.getCursor().Position() == 0 && s.getCursor().String() == " " {
+			s.cancel()
+			return
+		}
+
+		s.getCursor().Delete()
+		renderType = RenderTypePartialClear
+	case KeyCtrlE:
+		s.getCursor().MoveToEnd()
+		renderType = RenderTypeCursorOnly
+	case KeyCtrlH, KeyBackspace:
+		s.getCursor().Backspace()
+		renderType = RenderTypePartialClear
+		renderOffset = -1
+	case KeyCtrlU:
+		s.getCursor().DeleteToStart()
+		renderType = RenderTypePartialClear
+	case KeyEnter:
+		s.endCursorLocation()
+		if s.secret {
+			s.history.Discard()
+		} else {
 			s.history.Push()
-			s.getCursor().Flush()
-			s.enter()
-			s.printBuffer()
-			renderType = RenderTypeFull
-		case KeyCtrlK:
-			s.getCursor().DeleteToEnd()
-			renderType = RenderTypePartialClear
-		case KeyCtrlL:
-			s.terminal.EraseScreen(2)
-			renderType = RenderTypeFullTrim
-		case KeyCtrlP:
-			if s.history.Prev() {
-				s.getCursor().MoveToEnd()
-				renderType = RenderTypeFullTrim
-			} else {
-				renderType = RenderTypeCursorOnly
-			}
-		case KeyCtrlN:
-			if s.history.Next() {
-				s.getCursor().MoveToEnd()
-				renderType = RenderTypeFullTrim
-			} else {
-				renderType = RenderTypeCursorOnly
-			}
-		case KeyCtrlW:
-			s.getCursor().DeleteToPrevWord()
+		}
+		s.execute()
+		return
+	case KeyCtrlK:
+		s.getCursor().DeleteToEnd()
+		renderType = RenderTypePartialClear
+	case KeyCtrlL:
+		s.terminal.EraseScreen(2)
+		renderType = RenderTypeFullTrim
+	case KeyCtrlP, KeyCtrlN, KeyArrowUp, KeyArrowDown:
+		s.walkHistory(r)
+		return
+	case KeyCtrlR:
+		s.search = &search{
+			index:    s.history.Index(),
+			position: s.getCursor().Position(),
+		}
+		s.redraw()
+		return
+	case KeyCtrlW:
+		s.getCursor().DeleteToPrevWord()
+		renderType = RenderTypePartialClear
+	case KeyCtrlT:
+		updated := s.getCursor().Swap()
+
+		if updated == 0 {
+			renderType = RenderTypeSkip
+		} else {
 			renderType = RenderTypePartialClear
-		case KeyCtrlT:
-			updated := s.getCursor().Swap()
-
-			if updated == 0 {
-				renderType = RenderTypeSkip
-			} else {
-				renderType = RenderTypePartialClear
-				renderOffset = -1
+			renderOffset = -1
+		}
+	case KeyAltB:
+		s.getCursor().MoveToPrevWord()
+		renderType = RenderTypeCursorOnly
+	case KeyAltF:
+		s.getCursor().MoveToNextWord()
+		renderType = RenderTypeCursorOnly
+	case KeyCtrlC:
+		// the input is dropped and a new line is started
+		s.endCursorLocation()
+		s.history.Discard()
+		s.getCursor().Flush()
+		s.buffer.Push(terminal.NewPlainText("\n"))
+		s.printBuffer()
+		s.lines = 1
+		s.usedLines = 1
+		renderType = RenderTypeFull
+	default:
+		s.getCursor().WriteRune(r)
+		renderType = RenderTypePartial
+
+		s.logger.WriteMessages("char:", int(r))
+	}
+
+	s.logger.WriteMessages("RenderType: ", renderType)
+	s.render(renderType, renderOffset)
+	s.parse(ParseTypeFull)
+}
+
+// execute runs the entered command off the input loop, the shell waits for
+// it on executed.
+func (s *Shell) execute() {
+	s.running = true
+
+	go func() {
+		defer func() {
+			// the commands recover themselves, this keeps the session if
+			// anything else fails
+			if r := recover(); r != nil {
+				s.logger.WriteMessages("panic:", r, string(debug.Stack()))
+				s.buffer.Push(terminal.NewColoredText(fmt.Sprintf("%v: %v\n", parser.ErrPanic, r), s.getColor(parser.ObjectError)))
 			}
-		case KeyAltB:
-			s.getCursor().MoveToPrevWord()
-			renderType = RenderTypeCursorOnly
-		case KeyAltF:
-			s.getCursor().MoveToNextWord()
-			renderType = RenderTypeCursorOnly
-		case KeyCtrlC:
-			s.cancel()
+
+			s.executed <- struct{}{}
+		}()
+
+		s.enter()
+	}()
+}
+
+// finish prints the output of the executed command and a new prompt.
+func (s *Shell) finish() {
+	s.running = false
+	s.getCursor().Flush()
+	s.printBuffer()
+	// the output has scrolled the input away
+	s.lines = 1
+	s.usedLines = 1
+
+	s.render(RenderTypeFull, 0)
+	s.parse(ParseTypeFull)
+}
+
+// walkHistory moves to the previous or the next entry of the history. With
+// the prefix search the arrows skip the entries not starting with the text
+// before the cursor.
+func (s *Shell) walkHistory(key models.Rune) {
+	prefix := []rune(s.getCursor().String())[1 : s.getCursor().Position()+1]
+	up := key == KeyCtrlP || key == KeyArrowUp
+
+	var moved bool
+	switch {
+	case key < 0 && s.history.Options().PrefixSearch && len(prefix) > 0:
+		if up {
+			moved = s.history.PrevPrefix(string(prefix))
+		} else {
+			moved = s.history.NextPrefix(string(prefix))
+		}
+
+		s.getCursor().SetPosition(len(prefix))
+	case up:
+		moved = s.history.Prev()
+		s.getCursor().MoveToEnd()
+	default:
+		moved = s.history.Next()
+		s.getCursor().MoveToEnd()
+	}
+
+	if moved {
+		s.redraw()
+	}
+}
+
+// handleSearchKey edits the query of the history search. The keys not used
+// by the search accept the found line and are handled as usual.
+func (s *Shell) handleSearchKey(r models.Rune) bool {
+	switch {
+	case r == KeyCtrlR:
+		if len(s.search.query) > 0 {
+			s.find(true)
+		}
+	case r == KeyCtrlG || r == KeyEsc:
+		s.history.Seek(s.search.index)
+		s.getCursor().SetPosition(s.search.position)
+		s.search = nil
+	case r == KeyCtrlH || r == KeyBackspace:
+		if len(s.search.query) > 0 {
+			s.search.query = s.search.query[:len(s.search.query)-1]
+			s.history.Seek(s.search.index)
+			s.find(false)
+		}
+	case r >= ' ' && r != KeySuggest:
+		s.search.query = append(s.search.query, rune(r))
+		s.find(false)
+	default:
+		s.search = nil
+		s.redraw()
+		return false
+	}
+
+	s.redraw()
+	return true
+}
+
+// find moves to the entry containing the query and the cursor to the match.
+func (s *Shell) find(older bool) {
+	query := string(s.search.query)
+
+	if query == "" {
+		s.search.failed = false
+		return
+	}
+
+	if !s.history.Search(query, older) {
+		s.search.failed = true
+		return
+	}
+
+	s.search.failed = false
+
+	line := strings.TrimPrefix(s.getCursor().String(), " ")
+	s.getCursor().SetPosition(utf8.RuneCountInString(line[:strings.Index(line, query)]))
+}
+
+// complete types the common part of the suggestions or prints them all.
+func (s *Shell) complete() {
+	if s.getCursor().Position() != s.getCursor().Len()-1 {
+		return
+	}
+
+	resp := s.parser.Continue()
+
+	if resp == nil {
+		return
+	}
+
+	for _, c := range resp.Merged {
+		s.handleKey(models.Rune(c))
+	}
+
+	if resp.Merged != "" || len(resp.Options) == 0 {
+		return
+	}
+
+	s.buffer.Push(terminal.NewPlainText("\n"))
+
+	out := terminal.NewFlexibleTable()
+	var opts []string
+	for _, opt := range resp.Options {
+		opts = append(opts, opt.Option)
+		w := terminal.Word{}
+		switch opt.Level {
+		case parser.LevelTypePath:
+			color := s.colors[parser.ObjectPath]
+			w.SetColor(color)
+		case parser.LevelTypeCommand:
+			color := s.colors[parser.ObjectCommand]
+			w.SetColor(color)
+		case parser.LevelTypeFlag:
+			color := s.colors[parser.ObjectMandatoryFlag]
+			w.SetColor(color)
+		case parser.LevelTypeOption:
+			color := s.colors[parser.ObjectOption]
+			w.SetColor(color)
 		default:
-			s.getCursor().WriteRune(r)
-			renderType = RenderTypePartial
+			w.SetColor(terminal.ColorWhite)
+		}
+		w.SetText(opt.Option)
+		out.AddWord(w)
+	}
+	s.buffer.Push(out)
+	s.buffer.Push(terminal.NewPlainText("\n"))
+
+	s.logger.WriteMessages("Options: ", strings.Join(opts, ","))
+	s.logger.WriteMessages("Merged: ", resp.Merged)
+
+	s.printBuffer()
+	s.render(RenderTypeFullTrim, 0)
+	s.parse(ParseTypeFull)
+}
+
+// runLines executes the input line by line when the terminal is not a TTY.
+func (s *Shell) runLines(reader terminal.LineReader) {
+	for {
+		line, err := reader.ReadLine()
+
+		if err != nil {
+			if err != io.EOF {
+				s.logger.WriteMessages("ReadLine:", err.Error())
+			}
+			return
+		}
+
+		line = strings.ReplaceAll(line, "\t", " ")
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if resp := s.parser.ParseString(line); resp.Error != nil {
+			s.terminal.WriteToConsole(syntaxMessage(resp.Error, parser.SyntaxOffset(resp.Error, 0)+1) + "\n")
+			continue
+		}
+
+		l := s.buffer.Len()
+		resp, err := s.parser.Exec()
+
+		if err != nil {
+			// the output of the commands run before the failure is kept
+			s.printBuffer()
+			s.terminal.WriteToConsole(err.Error() + "\n")
+			continue
+		}
+
+		if resp.Error != nil {
+			offset := parser.SyntaxOffset(resp.Error, utf8.RuneCountInString(line))
+			s.terminal.WriteToConsole(syntaxMessage(resp.Error, offset+1) + "\n")
+			continue
+		}
 
-			s.logger.WriteMessages("char:", int(r))
+		if s.buffer.Len() > l {
+			s.buffer.Push(terminal.NewPlainText("\n"))
 		}
 
-		s.render(renderType, renderOffset)
-		s.parse(ParseTypeFull)
+		s.printBuffer()
 	}
 }
 
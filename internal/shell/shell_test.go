@@ -0,0 +1,390 @@
+package shell
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blkmlk/microshell/internal/builtin"
+	"github.com/blkmlk/microshell/internal/history"
+	"github.com/blkmlk/microshell/internal/parser"
+	"github.com/blkmlk/microshell/internal/terminal"
+	"github.com/stretchr/testify/require"
+)
+
+func testCommands() []*parser.Command {
+	commands := builtin.Commands()
+
+	for _, name := range []string{"add", "remove"} {
+		commands = append(commands, &parser.Command{
+			Type: parser.CommandTypeUser,
+			Path: []string{"ip", "firewall"},
+			Name: name,
+			Flags: map[string]*parser.Flag{
+				"network": {
+					Name:      "network",
+					Mandatory: true,
+					Number:    1,
+					ValueType: parser.ValueTypeString,
+				},
+			},
+		})
+	}
+
+	return commands
+}
+
+func TestShellComplete(t *testing.T) {
+	h := newHarness(t, 80, 24, testCommands()...)
+
+	h.Type("/ip fi")
+	h.Press("\t")
+	h.RequireLine(24, "[localhost@void] > /ip firewall")
+	h.RequireCursor(33, 24)
+
+	h.Press("\t")
+	h.RequireLine(22, "[localhost@void] > /ip firewall")
+	h.RequireLine(23, "add  remove")
+	h.RequireLine(24, "[localhost@void] > /ip firewall")
+
+	h.Type("a\t")
+	h.RequireLine(24, "[localhost@void] > /ip firewall add")
+	require.Equal(t, terminal.ColorBlue, h.terminal.Cell(21, 24).Color)
+}
+
+func TestShellEnter(t *testing.T) {
+	h := newHarness(t, 80, 24, testCommands()...)
+
+	h.Type(":put (2 + 3)\r")
+	h.RequireLine(22, "[localhost@void] > :put (2 + 3)")
+	h.RequireLine(23, "5")
+	h.RequireLine(24, "[localhost@void] >")
+
+	// :if shares the first letter with /ip
+	h.Type(":if (true) do={ :put 6 }\r")
+	h.RequireLine(23, "6")
+
+	h.Type(":put 1\r")
+	h.RequireLine(22, "[localhost@void] > :put 1")
+	h.RequireLine(23, "1")
+	h.RequireLine(24, "[localhost@void] >")
+}
+
+func TestShellRuntimeError(t *testing.T) {
+	h := newHarness(t, 80, 24, testCommands()...)
+
+	h.Type(":put 1; :put (1 / 0); :put 2\r")
+	h.RequireLine(21, "[localhost@void] > :put 1; :put (1 / 0); :put 2")
+	h.RequireLine(22, "1")
+	h.RequireLine(23, ":put: division by zero")
+	h.RequireLine(24, "[localhost@void] >")
+	require.Equal(t, terminal.ColorRed, h.terminal.Cell(1, 23).Color)
+}
+
+func TestShellSyntaxError(t *testing.T) {
+	h := newHarness(t, 80, 24, testCommands()...)
+
+	h.Type("/ip firewall adx\r")
+	h.RequireLine(21, "[localhost@void] > /ip firewall adx")
+	h.RequireLine(22, strings.Repeat(" ", 34)+"^")
+	h.RequireLine(23, "syntax error: unknown command `adx` under /ip firewall (line 1 column 16)")
+	h.RequireLine(24, "[localhost@void] >")
+	require.Equal(t, terminal.ColorRed, h.terminal.Cell(1, 23).Color)
+
+	h.Type("\r")
+	h.RequireLine(23, "[localhost@void] >")
+	h.RequireLine(24, "[localhost@void] >")
+}
+
+func TestShellEdit(t *testing.T) {
+	h := newHarness(t, 20, 5, testCommands()...)
+
+	h.Type("/ip firewall add 10.0.0.1")
+	h.RequireLine(3, "[localhost@void] > /")
+	h.RequireLine(4, "ip firewall add 10.0")
+	h.RequireLine(5, ".0.1")
+
+	h.Press("\x1b[D", "\x1b[D", "\x7f")
+	h.RequireLine(5, "..1")
+	h.RequireCursor(2, 5)
+
+	h.Press("\x01", "\x0b")
+	h.RequireLine(3, "[localhost@void] >")
+	h.RequireLine(4, "")
+	h.RequireCursor(20, 3)
+}
+
+func TestShellPaste(t *testing.T) {
+	h := newHarness(t, 80, 5, testCommands()...)
+
+	// the runes of a read are typed unless it is an escape sequence
+	h.Press(":pu", "t 1", "\x1b[D", "\x1b[D", "abc")
+	h.RequireLine(5, "[localhost@void] > :putabc 1")
+	h.RequireCursor(27, 5)
+}
+
+// waitCommand returns a command which runs until its context is done, started
+// receives when it runs.
+func waitCommand(timeout time.Duration, started chan<- struct{}) *parser.Command {
+	return &parser.Command{
+		Type:    parser.CommandTypeUser,
+		Name:    "wait",
+		Timeout: timeout,
+		ExecFunc: func(ctx parser.Context, flags parser.FlagValues, options parser.Options) (parser.Value, error) {
+			if started != nil {
+				started <- struct{}{}
+			}
+
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+}
+
+func TestShellInterrupt(t *testing.T) {
+	started := make(chan struct{})
+	h := newHarness(t, 80, 24, append(testCommands(), waitCommand(0, started))...)
+
+	h.Start(":put 1; /wait; :put 2")
+	<-started
+	require.True(t, h.shell.running)
+
+	h.Press("\x03")
+	h.RequireLine(21, "[localhost@void] > :put 1; /wait; :put 2")
+	h.RequireLine(22, "1")
+	h.RequireLine(23, "interrupted")
+	h.RequireLine(24, "[localhost@void] >")
+	require.Equal(t, terminal.ColorRed, h.terminal.Cell(1, 23).Color)
+	require.False(t, h.closed)
+
+	// without a command Ctrl-C drops the input
+	h.Type(":put 3")
+	h.Press("\x03")
+	h.RequireLine(23, "[localhost@void] > :put 3")
+	h.RequireLine(24, "[localhost@void] >")
+	require.False(t, h.closed)
+
+	h.Type("\r")
+	h.RequireLine(24, "[localhost@void] >")
+}
+
+func TestShellInterruptResized(t *testing.T) {
+	started := make(chan struct{})
+	h := newHarness(t, 80, 24, append(testCommands(), waitCommand(0, started))...)
+
+	h.Start("/wait")
+	<-started
+
+	h.Resize(60, 24)
+	require.True(t, h.shell.running)
+
+	done := make(chan struct{})
+	go func() {
+		h.Press("\x03")
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the command isn't interrupted")
+	}
+
+	h.RequireLine(23, "interrupted")
+	h.RequireLine(24, "[localhost@void] >")
+}
+
+func TestShellPanic(t *testing.T) {
+	h := newHarness(t, 80, 24, append(testCommands(), &parser.Command{
+		Type: parser.CommandTypeUser,
+		Name: "crash",
+		ExecFunc: func(ctx parser.Context, flags parser.FlagValues, options parser.Options) (parser.Value, error) {
+			panic("broken")
+		},
+	})...)
+
+	h.Type(":put 1; /crash\r")
+	h.RequireLine(22, "1")
+	h.RequireLine(23, "/crash: panic: broken")
+	h.RequireLine(24, "[localhost@void] >")
+
+	h.Type(":put 2\r")
+	h.RequireLine(23, "2")
+}
+
+func TestShellTimeout(t *testing.T) {
+	h := newHarness(t, 80, 24, append(testCommands(), waitCommand(10*time.Millisecond, nil))...)
+
+	h.Type("/wait\r")
+	h.RequireLine(23, "/wait: timed out after 10ms")
+	h.RequireLine(24, "[localhost@void] >")
+}
+
+func TestShellCtrlD(t *testing.T) {
+	h := newHarness(t, 80, 24)
+
+	h.Type("a")
+	h.Press("\x01", "\x04")
+	require.False(t, h.closed)
+
+	h.Press("\x04")
+	require.True(t, h.closed)
+}
+
+func TestShellResize(t *testing.T) {
+	h := newHarness(t, 30, 6, testCommands()...)
+
+	h.Type(":put 1\r")
+	h.Type("/ip firewall add 10.0.0.1")
+	h.Press("\x1b[D")
+	h.RequireLine(5, "[localhost@void] > /ip firewal")
+	h.RequireLine(6, "l add 10.0.0.1")
+	h.RequireCursor(14, 6)
+
+	h.Resize(20, 6)
+	h.RequireLine(3, "1")
+	h.RequireLine(4, "[localhost@void] > /")
+	h.RequireLine(5, "ip firewall add 10.0")
+	h.RequireLine(6, ".0.1")
+	h.RequireCursor(4, 6)
+
+	h.Resize(60, 5)
+	h.RequireLine(4, "")
+	h.RequireLine(5, "[localhost@void] > /ip firewall add 10.0.0.1")
+	h.RequireCursor(44, 5)
+
+	h.Type("5")
+	h.RequireLine(5, "[localhost@void] > /ip firewall add 10.0.0.51")
+}
+
+func TestShellWideRunes(t *testing.T) {
+	h := newHarness(t, 24, 5, testCommands()...)
+
+	h.Type(`:put "при世界"`)
+	h.RequireLine(4, "[localhost@void] > :put")
+	h.RequireLine(5, `"при世界"`)
+	h.RequireCursor(10, 5)
+
+	h.Press("\x1b[D", "\x1b[D", "\x7f")
+	h.RequireLine(5, `"при界"`)
+	h.RequireCursor(5, 5)
+
+	h.Type("\r")
+	h.RequireLine(3, `"при界"`)
+	h.RequireLine(4, "при界")
+	h.RequireLine(5, "[localhost@void] >")
+
+	// the wide rune which doesn't fit is moved to the next line
+	h.Type(":pu 世")
+	h.RequireLine(4, "[localhost@void] > :pu")
+	h.RequireLine(5, "世")
+	h.RequireCursor(3, 5)
+}
+
+func TestShellHistorySecret(t *testing.T) {
+	commands := append(testCommands(), &parser.Command{
+		Type: parser.CommandTypeUser,
+		Path: []string{"user"},
+		Name: "set",
+		Flags: map[string]*parser.Flag{
+			"password": {
+				Name:      "password",
+				Secret:    true,
+				ValueType: parser.ValueTypeString,
+			},
+		},
+	})
+
+	h := newHarness(t, 80, 24, commands...)
+
+	h.Type(":put 1\r")
+	h.Type("/user set password=pass\r")
+	h.RequireLine(23, "[localhost@void] > /user set password=pass")
+
+	h.Press("\x10")
+	h.RequireLine(24, "[localhost@void] > :put 1")
+}
+
+func TestShellHistorySearch(t *testing.T) {
+	h := newHarness(t, 60, 8, testCommands()...)
+
+	h.Type("/ip firewall add 10.0.0.1\r")
+	h.Type(":put 1\r")
+	h.Type("/ip firewall add 10.0.0.2\r")
+
+	h.Press("\x12")
+	h.RequireLine(8, "(reverse-i-search)'':")
+
+	h.Type("fire")
+	h.RequireLine(8, "(reverse-i-search)'fire': /ip firewall add 10.0.0.2")
+	h.RequireCursor(31, 8)
+
+	h.Press("\x12")
+	h.RequireLine(8, "(reverse-i-search)'fire': /ip firewall add 10.0.0.1")
+
+	h.Press("\x12")
+	h.RequireLine(8, "(failed reverse-i-search)'fire': /ip firewall add 10.0.0.1")
+
+	h.Press("\x7f")
+	h.RequireLine(8, "(reverse-i-search)'fir': /ip firewall add 10.0.0.2")
+
+	h.Press("\x07")
+	h.RequireLine(8, "[localhost@void] >")
+	h.RequireCursor(20, 8)
+
+	h.Press("\x12")
+	h.Type("put")
+	h.Press("\x1b[C")
+	h.RequireLine(8, "[localhost@void] > :put 1")
+	h.RequireCursor(22, 8)
+
+	h.Press("\r")
+	h.RequireLine(6, "[localhost@void] > :put 1")
+	h.RequireLine(7, "1")
+	h.RequireLine(8, "[localhost@void] >")
+}
+
+func TestShellHistorySearchEdited(t *testing.T) {
+	h := newHarness(t, 60, 8, testCommands()...)
+
+	h.Type(":put 1\r")
+	h.Press("\x1b[A", "\x15", "\x12")
+	h.Type("put")
+	h.RequireLine(8, "(failed reverse-i-search)'put':")
+
+	h.Press("\x07")
+	h.Type(":put 2")
+	h.Press("\x12")
+	h.Type("put")
+	h.RequireLine(8, "(reverse-i-search)'put': :put 2")
+	h.RequireCursor(27, 8)
+}
+
+func TestShellHistoryPrefix(t *testing.T) {
+	h := newHarness(t, 60, 8, testCommands()...)
+	require.NoError(t, h.shell.history.Open(history.Options{PrefixSearch: true}))
+
+	h.Type("/ip firewall add 10.0.0.1\r")
+	h.Type(":put 1\r")
+	h.Type("/ip firewall remove 10.0.0.1\r")
+	h.Type("/ip firewall add 10.0.0.2\r")
+
+	h.Type("/ip firewall a")
+	h.Press("\x1b[A")
+	h.RequireLine(8, "[localhost@void] > /ip firewall add 10.0.0.2")
+	h.RequireCursor(34, 8)
+
+	h.Press("\x1b[A")
+	h.RequireLine(8, "[localhost@void] > /ip firewall add 10.0.0.1")
+
+	h.Press("\x1b[A")
+	h.RequireLine(8, "[localhost@void] > /ip firewall add 10.0.0.1")
+
+	h.Press("\x1b[B", "\x1b[B")
+	h.RequireLine(8, "[localhost@void] > /ip firewall a")
+
+	h.Press("\x10")
+	h.RequireLine(8, "[localhost@void] > /ip firewall add 10.0.0.2")
+	h.Press("\x10")
+	h.RequireLine(8, "[localhost@void] > /ip firewall remove 10.0.0.1")
+}
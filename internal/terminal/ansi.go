@@ -0,0 +1,151 @@
+package terminal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// ansi writes the escape sequences shared by the terminals drawing on a VT100
+// compatible screen.
+type ansi struct {
+	out          io.Writer
+	currentColor Color
+
+	sizeMu  sync.RWMutex
+	width   int
+	height  int
+	resized chan struct{}
+}
+
+func (t *ansi) Width() int {
+	t.sizeMu.RLock()
+	defer t.sizeMu.RUnlock()
+
+	return t.width
+}
+
+func (t *ansi) Height() int {
+	t.sizeMu.RLock()
+	defer t.sizeMu.RUnlock()
+
+	return t.height
+}
+
+// Resized returns the channel notified after the size of the terminal has
+// changed.
+func (t *ansi) Resized() <-chan struct{} {
+	t.sizeMu.Lock()
+	defer t.sizeMu.Unlock()
+
+	return t.resizedChannel()
+}
+
+func (t *ansi) setSize(width, height int) {
+	t.sizeMu.Lock()
+	defer t.sizeMu.Unlock()
+
+	changed := t.width != 0 && (t.width != width || t.height != height)
+
+	t.width = width
+	t.height = height
+
+	if changed {
+		select {
+		case t.resizedChannel() <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (t *ansi) resizedChannel() chan struct{} {
+	if t.resized == nil {
+		t.resized = make(chan struct{}, 1)
+	}
+
+	return t.resized
+}
+
+func (t *ansi) WriteToConsole(s string) int {
+	n, _ := io.WriteString(t.out, s)
+
+	if f, ok := t.out.(*os.File); ok {
+		f.Sync()
+	}
+
+	return n
+}
+
+func (t *ansi) Color() Color {
+	return t.currentColor
+}
+
+func (t *ansi) SetColor(color Color) {
+	t.WriteToConsole(fmt.Sprintf("\x1b[%dm", color+30))
+	t.currentColor = color
+}
+
+func (t *ansi) MoveCursorToPosition(x, y int) {
+	t.WriteToConsole(fmt.Sprintf("\x1b[%d;%dH", y, x))
+}
+
+func (t *ansi) MoveCursorToStart() {
+	t.WriteToConsole("\x1b[0G")
+}
+
+func (t *ansi) MoveCursorBack(steps int) {
+	t.WriteToConsole(fmt.Sprintf("\x1b[%dD", steps))
+}
+
+func (t *ansi) MoveCursorForward(steps int) {
+	t.WriteToConsole(fmt.Sprintf("\x1b[%dC", steps))
+}
+
+func (t *ansi) MoveCursorUp(steps int) {
+	t.WriteToConsole(fmt.Sprintf("\x1b[%dA", steps))
+}
+
+func (t *ansi) MoveCursorDown(steps int) {
+	t.WriteToConsole(fmt.Sprintf("\x1b[%dB", steps))
+}
+
+func (t *ansi) MoveCursorTo(col int) {
+	t.WriteToConsole(fmt.Sprintf("\x1b[%dG", col))
+}
+
+func (t *ansi) EraseScreen(mode int) {
+	t.WriteToConsole(fmt.Sprintf("\x1b[%dJ", mode))
+}
+
+func (t *ansi) ShowCursor() {
+	t.WriteToConsole("\x1b[?25h")
+}
+
+func (t *ansi) HideCursor() {
+	t.WriteToConsole("\x1b[?25l")
+}
+
+func (t *ansi) EraseToEnd() {
+	t.WriteToConsole("\x1b[0K")
+}
+
+func (t *ansi) EraseToStart() {
+	t.WriteToConsole("\x1b[1K")
+}
+
+func (t *ansi) EraseLine() {
+	t.WriteToConsole("\x1b[2K")
+}
+
+func (t *ansi) InsertLine() {
+	t.WriteToConsole("\x1b[10L")
+}
+
+func (t *ansi) ScrollUp() {
+	t.WriteToConsole("\x1b[1S")
+}
+
+func (t *ansi) ScrollDown() {
+	t.WriteToConsole("\x1b[1T")
+}
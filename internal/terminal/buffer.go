@@ -31,6 +31,11 @@ func (b *buffer) Len() int {
 	return b.stack.Len()
 }
 
+// NewBuffer returns an empty buffer, e.g. for the output of a background job.
+func NewBuffer() Buffer {
+	return newBuffer()
+}
+
 func newBuffer() Buffer {
 	return &buffer{stack: new(list.List)}
 }
@@ -42,3 +42,9 @@ type Terminal interface {
 	ScrollUp()
 	ScrollDown()
 }
+
+// Resizer is implemented by the terminals whose size may change while the
+// shell is running.
+type Resizer interface {
+	Resized() <-chan struct{}
+}
@@ -0,0 +1,310 @@
+package terminal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// ListMode is the form NewItemList prints the items in.
+type ListMode int
+
+const (
+	// ListTable prints a row for every item under the names of the columns.
+	ListTable ListMode = iota
+	// ListDetail prints the values of every item as name=value wrapped at
+	// the width of the terminal.
+	ListDetail
+	// ListTerse prints a line of name=value for every item.
+	ListTerse
+)
+
+// ListFlag is a letter of the flags column, e.g. X for disabled.
+type ListFlag struct {
+	Letter string
+	Name   string
+}
+
+// ListItem is an item of NewItemList. Values are in the order of the
+// columns, an empty value is unset.
+type ListItem struct {
+	Number  int
+	Flags   string
+	Comment string
+	Values  []string
+}
+
+var _ Output = NewItemList(ListTable, nil, nil, nil)
+
+// NewItemList returns the items in the mode, the flags are explained above
+// them.
+func NewItemList(mode ListMode, flags []ListFlag, columns []string, items []ListItem) *itemList {
+	return &itemList{
+		mode:    mode,
+		flags:   flags,
+		columns: columns,
+		items:   items,
+	}
+}
+
+type itemList struct {
+	mode    ListMode
+	flags   []ListFlag
+	columns []string
+	items   []ListItem
+}
+
+func (l *itemList) Words(width, height int) []Word {
+	var lines []string
+
+	switch l.mode {
+	case ListDetail:
+		lines = append(l.legend(), l.detail(width)...)
+	case ListTerse:
+		lines = l.terse()
+	default:
+		lines = append(l.legend(), l.table(width)...)
+	}
+
+	if len(lines) == 0 {
+		return nil
+	}
+
+	return []Word{NewWord(strings.Join(lines, "\n"), ColorWhite)}
+}
+
+func (l *itemList) legend() []string {
+	if len(l.flags) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(l.flags))
+	for _, flag := range l.flags {
+		names = append(names, flag.Letter+" - "+flag.Name)
+	}
+
+	return []string{"Flags: " + strings.Join(names, ", ")}
+}
+
+// prefix returns the number and the flags of the item, the lines going on
+// with the item are indented by as much.
+func (l *itemList) prefix(number, flags string) string {
+	width := 1
+	for _, item := range l.items {
+		if len(item.Flags) > width {
+			width = len(item.Flags)
+		}
+	}
+
+	return fmt.Sprintf("%3s %-*s", number, width, flags)
+}
+
+// table sizes the columns to the values, the widest of them are narrowed
+// until the rows fit the width and their values are wrapped.
+func (l *itemList) table(width int) []string {
+	widths := make([]int, len(l.columns))
+	for i, column := range l.columns {
+		widths[i] = runewidth.StringWidth(column)
+	}
+
+	for _, item := range l.items {
+		for i, value := range item.Values {
+			if w := runewidth.StringWidth(value); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	if width > 0 {
+		fit(widths, l.columns, width-runewidth.StringWidth(l.prefix("", ""))-2*len(widths))
+	}
+
+	row := func(prefix string, values []string) []string {
+		cells := make([][]string, len(values))
+		height := 1
+
+		for i, value := range values {
+			cells[i] = split(value, widths[i])
+			if len(cells[i]) > height {
+				height = len(cells[i])
+			}
+		}
+
+		lines := make([]string, height)
+		for n := range lines {
+			line := prefix
+			if n > 0 {
+				line = strings.Repeat(" ", runewidth.StringWidth(prefix))
+			}
+
+			for i := range values {
+				var cell string
+				if n < len(cells[i]) {
+					cell = cells[i][n]
+				}
+
+				line += "  " + runewidth.FillRight(cell, widths[i])
+			}
+
+			lines[n] = strings.TrimRight(line, " ")
+		}
+
+		return lines
+	}
+
+	header := make([]string, len(l.columns))
+	for i, column := range l.columns {
+		header[i] = strings.ToUpper(column)
+	}
+
+	lines := row(l.prefix("#", ""), header)
+
+	for _, item := range l.items {
+		prefix := l.prefix(strconv.Itoa(item.Number), item.Flags)
+
+		if item.Comment != "" {
+			lines = append(lines, prefix+"  ;;; "+item.Comment)
+			prefix = l.prefix("", "")
+		}
+
+		lines = append(lines, row(prefix, item.Values)...)
+	}
+
+	return lines
+}
+
+// fit narrows the widest columns to the width, down to the width of their
+// names.
+func fit(widths []int, columns []string, width int) {
+	total := 0
+	for _, w := range widths {
+		total += w
+	}
+
+	for ; total > width; total-- {
+		widest := -1
+
+		for i, w := range widths {
+			if w > runewidth.StringWidth(columns[i]) && (widest < 0 || w > widths[widest]) {
+				widest = i
+			}
+		}
+
+		if widest < 0 {
+			return
+		}
+
+		widths[widest]--
+	}
+}
+
+// split cuts the value into the lines of the width in screen cells, at the
+// spaces if it can.
+func split(value string, width int) []string {
+	if runewidth.StringWidth(value) <= width {
+		return []string{value}
+	}
+
+	var lines []string
+	var line string
+
+	for _, word := range strings.Fields(value) {
+		switch {
+		case line == "":
+			line = word
+		case runewidth.StringWidth(line)+1+runewidth.StringWidth(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+
+		// a word wider than the column is cut
+		for runewidth.StringWidth(line) > width {
+			head := runewidth.Truncate(line, width, "")
+			if head == "" {
+				break
+			}
+
+			lines = append(lines, head)
+			line = line[len(head):]
+		}
+	}
+
+	return append(lines, line)
+}
+
+func (l *itemList) detail(width int) []string {
+	var lines []string
+
+	for i, item := range l.items {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+
+		prefix := l.prefix(strconv.Itoa(item.Number), item.Flags) + "  "
+		indent := strings.Repeat(" ", runewidth.StringWidth(prefix))
+
+		if item.Comment != "" {
+			lines = append(lines, prefix+";;; "+item.Comment)
+			prefix = indent
+		}
+
+		line := prefix
+		for _, pair := range l.pairs(item) {
+			switch {
+			case line == prefix:
+				line += pair
+			case width > 0 && runewidth.StringWidth(line)+1+runewidth.StringWidth(pair) > width:
+				lines = append(lines, line)
+				line = indent + pair
+			default:
+				line += " " + pair
+			}
+		}
+
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+
+	return lines
+}
+
+func (l *itemList) terse() []string {
+	lines := make([]string, 0, len(l.items))
+
+	for _, item := range l.items {
+		pairs := l.pairs(item)
+		if item.Comment != "" {
+			pairs = append([]string{"comment=" + quote(item.Comment)}, pairs...)
+		}
+
+		line := l.prefix(strconv.Itoa(item.Number), item.Flags) + "  " + strings.Join(pairs, " ")
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+
+	return lines
+}
+
+// pairs returns the values set as name=value.
+func (l *itemList) pairs(item ListItem) []string {
+	var pairs []string
+
+	for i, value := range item.Values {
+		if value != "" {
+			pairs = append(pairs, l.columns[i]+"="+quote(value))
+		}
+	}
+
+	return pairs
+}
+
+// quote puts the value in quotes if it can't be read back without them.
+func quote(value string) string {
+	if strings.ContainsAny(value, " \t\"\\;=") {
+		return strconv.Quote(value)
+	}
+
+	return value
+}
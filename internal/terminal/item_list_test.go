@@ -0,0 +1,57 @@
+package terminal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestItemList(t *testing.T) {
+	text := func(out Output, width int) string {
+		var result strings.Builder
+		for _, w := range out.Words(width, 0) {
+			result.WriteString(w.Text())
+		}
+		return result.String()
+	}
+
+	flags := []ListFlag{{Letter: "X", Name: "disabled"}}
+	columns := []string{"name", "description"}
+	items := []ListItem{
+		{Number: 0, Values: []string{"ssh", "secure shell server"}},
+		{Number: 1, Flags: "X", Comment: "old", Values: []string{"telnet", ""}},
+	}
+
+	table := NewItemList(ListTable, flags, columns, items)
+
+	require.Equal(t, `Flags: X - disabled
+  #    NAME    DESCRIPTION
+  0    ssh     secure shell server
+  1 X  ;;; old
+       telnet`, text(table, 0))
+
+	// the widest column is narrowed and its values wrapped
+	require.Equal(t, `Flags: X - disabled
+  #    NAME    DESCRIPTION
+  0    ssh     secure shell
+               server
+  1 X  ;;; old
+       telnet`, text(table, 27))
+
+	detail := NewItemList(ListDetail, flags, columns, items)
+
+	require.Equal(t, `Flags: X - disabled
+  0    name=ssh
+       description="secure shell server"
+
+  1 X  ;;; old
+       name=telnet`, text(detail, 30))
+
+	terse := NewItemList(ListTerse, flags, columns, items)
+
+	require.Equal(t, `  0    name=ssh description="secure shell server"
+  1 X  comment=old name=telnet`, text(terse, 30))
+
+	require.Equal(t, "Flags: X - disabled\n  #    NAME  DESCRIPTION", text(NewItemList(ListTable, flags, columns, nil), 0))
+}
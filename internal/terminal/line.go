@@ -0,0 +1,119 @@
+package terminal
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// LineReader is implemented by terminals that can't be switched to the raw
+// mode and deliver the input line by line.
+type LineReader interface {
+	ReadLine() (string, error)
+}
+
+var _ Terminal = NewLineTerminal(nil, nil)
+var _ LineReader = NewLineTerminal(nil, nil)
+
+// lineTerminal is used when the input or the output is not a TTY. It neither
+// moves the cursor nor changes colors.
+type lineTerminal struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func NewLineTerminal(in io.Reader, out io.Writer) *lineTerminal {
+	return &lineTerminal{
+		in:  bufio.NewReader(in),
+		out: out,
+	}
+}
+
+func isTerminal(fd uintptr) bool {
+	var st syscall.Termios
+	_, _, err := syscall.Syscall6(syscall.SYS_IOCTL, fd, uintptr(syscall.TCGETS), uintptr(unsafe.Pointer(&st)), 0, 0, 0)
+	return err == 0
+}
+
+func (t *lineTerminal) ReadLine() (string, error) {
+	line, err := t.in.ReadString('\n')
+
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (t *lineTerminal) ReadRunes() ([]rune, error) {
+	line, err := t.ReadLine()
+	if err != nil {
+		return nil, err
+	}
+
+	return []rune(line), nil
+}
+
+func (t *lineTerminal) WriteToConsole(s string) int {
+	n, _ := io.WriteString(t.out, s)
+
+	if f, ok := t.out.(*os.File); ok {
+		f.Sync()
+	}
+
+	return n
+}
+
+func (t *lineTerminal) ResetTerminal() error {
+	return nil
+}
+
+// Width returns 0 so the output is never wrapped.
+func (t *lineTerminal) Width() int {
+	return 0
+}
+
+func (t *lineTerminal) Height() int {
+	return 0
+}
+
+func (t *lineTerminal) Color() Color {
+	return ColorWhite
+}
+
+func (t *lineTerminal) SetColor(color Color) {}
+
+func (t *lineTerminal) MoveCursorToPosition(x, y int) {}
+
+func (t *lineTerminal) MoveCursorToStart() {}
+
+func (t *lineTerminal) MoveCursorBack(steps int) {}
+
+func (t *lineTerminal) MoveCursorForward(steps int) {}
+
+func (t *lineTerminal) MoveCursorUp(steps int) {}
+
+func (t *lineTerminal) MoveCursorDown(steps int) {}
+
+func (t *lineTerminal) MoveCursorTo(col int) {}
+
+func (t *lineTerminal) EraseScreen(mode int) {}
+
+func (t *lineTerminal) ShowCursor() {}
+
+func (t *lineTerminal) HideCursor() {}
+
+func (t *lineTerminal) EraseToEnd() {}
+
+func (t *lineTerminal) EraseToStart() {}
+
+func (t *lineTerminal) EraseLine() {}
+
+func (t *lineTerminal) InsertLine() {}
+
+func (t *lineTerminal) ScrollUp() {}
+
+func (t *lineTerminal) ScrollDown() {}
@@ -0,0 +1,37 @@
+package terminal
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLineTerminal(t *testing.T) {
+	out := new(bytes.Buffer)
+	term := NewLineTerminal(strings.NewReader("first\r\nsecond\nlast"), out)
+
+	line, err := term.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "first", line)
+
+	runes, err := term.ReadRunes()
+	require.NoError(t, err)
+	require.Equal(t, []rune("second"), runes)
+
+	line, err = term.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "last", line)
+
+	_, err = term.ReadLine()
+	require.Equal(t, io.EOF, err)
+
+	term.SetColor(ColorRed)
+	term.MoveCursorToPosition(1, 1)
+	term.EraseLine()
+	require.Equal(t, 4, term.WriteToConsole("text"))
+	require.Equal(t, "text", out.String())
+	require.Equal(t, 0, term.Width())
+}
@@ -1,5 +1,7 @@
 package terminal
 
+import "github.com/mattn/go-runewidth"
+
 type Output interface {
 	Words(width, height int) []Word
 }
@@ -16,8 +18,9 @@ func NewWord(text string, color Color) Word {
 	}
 }
 
+// Len returns the number of the screen cells the word takes.
 func (o *Word) Len() int {
-	return len(o.text)
+	return runewidth.StringWidth(o.text)
 }
 
 func (o *Word) Text() string {
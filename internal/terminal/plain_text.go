@@ -1,13 +1,25 @@
 package terminal
 
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
 var _ Output = NewPlainText("")
 
 func NewPlainText(text string) *plainText {
-	return &plainText{text: text}
+	return NewColoredText(text, ColorWhite)
+}
+
+// NewColoredText returns the text written in the color, e.g. an error.
+func NewColoredText(text string, color Color) *plainText {
+	return &plainText{text: text, color: color}
 }
 
 type plainText struct {
-	text string
+	text  string
+	color Color
 }
 
 func (p *plainText) SetText(text string) {
@@ -15,18 +27,36 @@ func (p *plainText) SetText(text string) {
 }
 
 func (p *plainText) Words(width, height int) []Word {
-	t := p.text
-	l := len(p.text)
-	result := make([]Word, 0, l/width+1)
-
-	for l > width {
-		result = append(result, NewWord(t, ColorWhite), NewWord("\n", ColorWhite))
-		l -= width
-		t = t[width:]
+	// the text is not wrapped when the width is unknown
+	if width <= 0 {
+		return []Word{NewWord(p.text, p.color)}
 	}
 
-	if t != "" {
-		result = append(result, NewWord(t, ColorWhite))
+	var result []Word
+	var line strings.Builder
+	var used int
+
+	// the lines are split by the screen cells as wide runes take two of them
+	for _, r := range p.text {
+		w := runewidth.RuneWidth(r)
+
+		if used+w > width && used > 0 {
+			result = append(result, NewWord(line.String(), p.color), NewWord("\n", p.color))
+			line.Reset()
+			used = 0
+		}
+
+		if r == '\n' {
+			used = 0
+		}
+
+		line.WriteRune(r)
+		used += w
 	}
+
+	if line.Len() > 0 {
+		result = append(result, NewWord(line.String(), p.color))
+	}
+
 	return result
 }
@@ -1,3 +1,26 @@
 package terminal
 
-// TODO: Write Tests
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlainText(t *testing.T) {
+	text := func(words []Word) []string {
+		var result []string
+		for _, w := range words {
+			result = append(result, w.Text())
+		}
+		return result
+	}
+
+	require.Equal(t, []string{"abc"}, text(NewPlainText("abc").Words(5, 1)))
+	require.Equal(t, []string{"abcde", "\n", "fg"}, text(NewPlainText("abcdefg").Words(5, 1)))
+	require.Equal(t, []string{"абвгд", "\n", "е"}, text(NewPlainText("абвгде").Words(5, 1)))
+	require.Equal(t, []string{"a世", "\n", "界b"}, text(NewPlainText("a世界b").Words(4, 1)))
+	require.Equal(t, []string{"abcdefg"}, text(NewPlainText("abcdefg").Words(0, 0)))
+
+	w := NewWord("世界", ColorWhite)
+	require.Equal(t, 4, w.Len())
+}
@@ -0,0 +1,205 @@
+package terminal
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+const (
+	telnetSE   = 240
+	telnetSB   = 250
+	telnetWill = 251
+	telnetWont = 252
+	telnetDo   = 253
+	telnetDont = 254
+	telnetIAC  = 255
+
+	telnetOptionEcho            = 1
+	telnetOptionSuppressGoAhead = 3
+	telnetOptionNAWS            = 31
+
+	telnetDefaultWidth  = 80
+	telnetDefaultHeight = 24
+)
+
+type telnetState int
+
+const (
+	telnetStateData telnetState = iota
+	telnetStateIAC
+	telnetStateOption
+	telnetStateSubnegotiation
+	telnetStateSubnegotiationIAC
+)
+
+var _ Terminal = NewTelnetTerminal(new(bytes.Buffer))
+
+// telnetTerminal draws on the screen of a telnet client. The server echoes
+// the input itself and the client reports its window size with NAWS.
+type telnetTerminal struct {
+	ansi
+	reader *bufio.Reader
+	writer io.Writer
+
+	state   telnetState
+	command byte
+	sb      []byte
+	cr      bool
+	pending []rune
+	// partial is the start of a rune the next read completes
+	partial []byte
+}
+
+func NewTelnetTerminal(conn io.ReadWriter) *telnetTerminal {
+	t := &telnetTerminal{
+		reader: bufio.NewReader(conn),
+		writer: conn,
+	}
+
+	t.out = crlfWriter{conn}
+	t.setSize(telnetDefaultWidth, telnetDefaultHeight)
+
+	t.negotiate(telnetWill, telnetOptionEcho)
+	t.negotiate(telnetWill, telnetOptionSuppressGoAhead)
+	t.negotiate(telnetDo, telnetOptionSuppressGoAhead)
+	t.negotiate(telnetDo, telnetOptionNAWS)
+
+	return t
+}
+
+func (t *telnetTerminal) ResetTerminal() error {
+	return nil
+}
+
+// ReadRunes returns a single key at a time as the client may send several of
+// them in one packet.
+func (t *telnetTerminal) ReadRunes() ([]rune, error) {
+	var buf [16]byte
+
+	for len(t.pending) == 0 {
+		n, err := t.reader.Read(buf[:])
+		if err != nil {
+			return nil, err
+		}
+
+		data := append(t.partial, t.filter(buf[:n])...)
+		data, t.partial = splitPartial(data)
+
+		t.pending = append(t.pending, []rune(string(data))...)
+	}
+
+	n := 1
+	if t.pending[0] == '\x1b' && len(t.pending) > 1 {
+		n = 2
+		if t.pending[1] == '[' && len(t.pending) > 2 {
+			n = 3
+		}
+	}
+
+	key := t.pending[:n:n]
+	t.pending = t.pending[n:]
+
+	return key, nil
+}
+
+// filter strips the telnet commands from the input and returns the data.
+func (t *telnetTerminal) filter(in []byte) []byte {
+	var data []byte
+
+	for _, b := range in {
+		switch t.state {
+		case telnetStateData:
+			switch {
+			case b == telnetIAC:
+				t.state = telnetStateIAC
+			case t.cr && (b == '\n' || b == 0):
+				// the enter key is sent as CR LF or CR NUL
+			default:
+				data = append(data, b)
+			}
+
+			t.cr = b == '\r'
+		case telnetStateIAC:
+			switch b {
+			case telnetIAC:
+				data = append(data, b)
+				t.state = telnetStateData
+			case telnetWill, telnetWont, telnetDo, telnetDont:
+				t.command = b
+				t.state = telnetStateOption
+			case telnetSB:
+				t.sb = t.sb[:0]
+				t.state = telnetStateSubnegotiation
+			default:
+				t.state = telnetStateData
+			}
+		case telnetStateOption:
+			t.answer(t.command, b)
+			t.state = telnetStateData
+		case telnetStateSubnegotiation:
+			if b == telnetIAC {
+				t.state = telnetStateSubnegotiationIAC
+			} else {
+				t.sb = append(t.sb, b)
+			}
+		case telnetStateSubnegotiationIAC:
+			switch b {
+			case telnetSE:
+				t.subnegotiation(t.sb)
+				t.state = telnetStateData
+			case telnetIAC:
+				t.sb = append(t.sb, b)
+				t.state = telnetStateSubnegotiation
+			default:
+				t.state = telnetStateData
+			}
+		}
+	}
+
+	return data
+}
+
+// answer refuses the options the terminal doesn't support.
+func (t *telnetTerminal) answer(command, option byte) {
+	switch command {
+	case telnetDo:
+		if option != telnetOptionEcho && option != telnetOptionSuppressGoAhead {
+			t.negotiate(telnetWont, option)
+		}
+	case telnetWill:
+		if option != telnetOptionNAWS && option != telnetOptionSuppressGoAhead {
+			t.negotiate(telnetDont, option)
+		}
+	}
+}
+
+func (t *telnetTerminal) subnegotiation(sb []byte) {
+	if len(sb) < 5 || sb[0] != telnetOptionNAWS {
+		return
+	}
+
+	width := int(sb[1])<<8 | int(sb[2])
+	height := int(sb[3])<<8 | int(sb[4])
+
+	if width > 0 && height > 0 {
+		t.setSize(width, height)
+	}
+}
+
+func (t *telnetTerminal) negotiate(command, option byte) {
+	_, _ = t.writer.Write([]byte{telnetIAC, command, option})
+}
+
+// crlfWriter translates line feeds to the network virtual terminal newlines.
+type crlfWriter struct {
+	w io.Writer
+}
+
+func (c crlfWriter) Write(p []byte) (int, error) {
+	if _, err := c.w.Write(bytes.ReplaceAll(p, []byte{'\n'}, []byte{'\r', '\n'})); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,102 @@
+package terminal
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type telnetConn struct {
+	in io.Reader
+	bytes.Buffer
+}
+
+func (c *telnetConn) Read(p []byte) (int, error) {
+	return c.in.Read(p)
+}
+
+const telnetNOP = 241
+
+func TestTelnetTerminal(t *testing.T) {
+	in := []byte{
+		telnetIAC, telnetWill, telnetOptionNAWS,
+		telnetIAC, telnetSB, telnetOptionNAWS, 0, 120, 0, 40, telnetIAC, telnetSE,
+		telnetIAC, telnetDo, 24,
+		'l', 's', '\r', 0,
+		'a', telnetIAC, telnetNOP, '\r', '\n',
+	}
+
+	conn := &telnetConn{in: bytes.NewReader(in)}
+	term := NewTelnetTerminal(conn)
+
+	require.Equal(t, []byte{
+		telnetIAC, telnetWill, telnetOptionEcho,
+		telnetIAC, telnetWill, telnetOptionSuppressGoAhead,
+		telnetIAC, telnetDo, telnetOptionSuppressGoAhead,
+		telnetIAC, telnetDo, telnetOptionNAWS,
+	}, conn.Bytes())
+	require.Equal(t, telnetDefaultWidth, term.Width())
+	require.Equal(t, telnetDefaultHeight, term.Height())
+	conn.Reset()
+
+	var runes []rune
+	for {
+		rs, err := term.ReadRunes()
+		if err == io.EOF {
+			break
+		}
+
+		require.NoError(t, err)
+		runes = append(runes, rs...)
+	}
+
+	require.Equal(t, []rune("ls\ra\r"), runes)
+	require.Equal(t, 120, term.Width())
+	require.Equal(t, 40, term.Height())
+	require.Len(t, term.Resized(), 1)
+	require.Equal(t, []byte{telnetIAC, telnetWont, 24}, conn.Bytes())
+
+	conn.Reset()
+	term.WriteToConsole("a\nb")
+	require.Equal(t, "a\r\nb", conn.String())
+}
+
+// chunkReader returns a chunk at a time like the packets of a connection.
+type chunkReader struct {
+	chunks [][]byte
+}
+
+func (c *chunkReader) Read(p []byte) (int, error) {
+	if len(c.chunks) == 0 {
+		return 0, io.EOF
+	}
+
+	n := copy(p, c.chunks[0])
+	c.chunks = c.chunks[1:]
+
+	return n, nil
+}
+
+func TestTelnetTerminalSplitRune(t *testing.T) {
+	text := []byte("aé世🙂")
+
+	conn := &telnetConn{in: &chunkReader{chunks: [][]byte{
+		text[:2], text[2:4], text[4:5], text[5:8], text[8:],
+	}}}
+	term := NewTelnetTerminal(conn)
+
+	var runes []rune
+	for {
+		rs, err := term.ReadRunes()
+		if err == io.EOF {
+			break
+		}
+
+		require.NoError(t, err)
+		runes = append(runes, rs...)
+	}
+
+	require.Equal(t, []rune("aé世🙂"), runes)
+}
@@ -1,9 +1,10 @@
 package terminal
 
 import (
-	"fmt"
 	"os"
+	"os/signal"
 	"syscall"
+	"unicode/utf8"
 	"unsafe"
 )
 
@@ -20,15 +21,22 @@ const (
 	ColorWhite
 )
 
+var _ Resizer = &terminal{}
+
 type terminal struct {
-	in           uintptr
-	term         syscall.Termios
-	width        int
-	height       int
-	currentColor Color
+	ansi
+	in    uintptr
+	term  syscall.Termios
+	winch chan os.Signal
+	// partial is the start of a rune the next read completes
+	partial []byte
 }
 
 func newTerminal() (Terminal, error) {
+	if !isTerminal(os.Stdin.Fd()) || !isTerminal(os.Stdout.Fd()) {
+		return NewLineTerminal(os.Stdin, os.Stdout), nil
+	}
+
 	var t terminal
 
 	t.in = os.Stdin.Fd()
@@ -46,6 +54,32 @@ func newTerminal() (Terminal, error) {
 		return nil, err
 	}
 
+	width, height, err := windowSize(t.in)
+	if err != nil {
+		return nil, err
+	}
+
+	t.out = os.Stdout
+	t.setSize(width, height)
+
+	t.winch = make(chan os.Signal, 1)
+	signal.Notify(t.winch, syscall.SIGWINCH)
+
+	go t.watchSize()
+
+	return &t, nil
+}
+
+// watchSize updates the size every time the window is resized.
+func (t *terminal) watchSize() {
+	for range t.winch {
+		if width, height, err := windowSize(t.in); err == nil {
+			t.setSize(width, height)
+		}
+	}
+}
+
+func windowSize(fd uintptr) (width, height int, err error) {
 	type termInfo struct {
 		Height uint16
 		Width  uint16
@@ -54,16 +88,16 @@ func newTerminal() (Terminal, error) {
 	}
 
 	var ti termInfo
-	if _, _, err := syscall.Syscall(syscall.SYS_IOCTL, uintptr(syscall.Stdin), uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ti))); err != 0 {
-		return nil, err
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ti))); errno != 0 {
+		return 0, 0, errno
 	}
-	t.height = int(ti.Height)
-	t.width = int(ti.Width)
 
-	return &t, nil
+	return int(ti.Width), int(ti.Height), nil
 }
 
 func (t *terminal) ResetTerminal() error {
+	signal.Stop(t.winch)
+
 	if _, _, err := syscall.Syscall6(syscall.SYS_IOCTL, t.in, uintptr(syscall.TCSETS), uintptr(unsafe.Pointer(&t.term)), 0, 0, 0); err != 0 {
 		return err
 	}
@@ -71,14 +105,6 @@ func (t *terminal) ResetTerminal() error {
 	return nil
 }
 
-func (t *terminal) Width() int {
-	return t.width
-}
-
-func (t *terminal) Height() int {
-	return t.height
-}
-
 func (t *terminal) ReadRunes() ([]rune, error) {
 	var buf [16]byte
 	n, err := syscall.Read(int(t.in), buf[:])
@@ -91,84 +117,27 @@ func (t *terminal) ReadRunes() ([]rune, error) {
 	if buf[n-1] == '\n' {
 		n--
 	}
-	return []rune(string(buf[:n])), nil
-}
 
-func (t *terminal) WriteToConsole(s string) int {
-	n, _ := os.Stdout.WriteString(s)
-	os.Stdout.Sync()
-	return n
-}
+	var data []byte
+	data, t.partial = splitPartial(append(t.partial, buf[:n]...))
 
-func (t *terminal) Color() Color {
-	return t.currentColor
+	return []rune(string(data)), nil
 }
 
-func (t *terminal) SetColor(color Color) {
-	t.WriteToConsole(fmt.Sprintf("\x1b[%dm", color+30))
-	t.currentColor = color
-}
+// splitPartial cuts the incomplete rune off the end of the data, a rune may
+// be split between two reads.
+func splitPartial(data []byte) ([]byte, []byte) {
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if !utf8.RuneStart(data[i]) {
+			continue
+		}
 
-func (t *terminal) MoveCursorToPosition(x, y int) {
-	t.WriteToConsole(fmt.Sprintf("\x1b[%d;%dH", y, x))
-}
+		if utf8.FullRune(data[i:]) {
+			break
+		}
 
-func (t *terminal) MoveCursorToStart() {
-	t.WriteToConsole("\x1b[0G")
-}
-
-func (t *terminal) MoveCursorBack(steps int) {
-	t.WriteToConsole(fmt.Sprintf("\x1b[%dD", steps))
-}
-
-func (t *terminal) MoveCursorForward(steps int) {
-	t.WriteToConsole(fmt.Sprintf("\x1b[%dC", steps))
-}
-
-func (t *terminal) MoveCursorUp(steps int) {
-	t.WriteToConsole(fmt.Sprintf("\x1b[%dA", steps))
-}
-
-func (t *terminal) MoveCursorDown(steps int) {
-	t.WriteToConsole(fmt.Sprintf("\x1b[%dB", steps))
-}
-
-func (t *terminal) MoveCursorTo(col int) {
-	t.WriteToConsole(fmt.Sprintf("\x1b[%dG", col))
-}
-
-func (t *terminal) EraseScreen(mode int) {
-	t.WriteToConsole(fmt.Sprintf("\x1b[%dJ", mode))
-}
-
-func (t *terminal) ShowCursor() {
-	t.WriteToConsole("\x1b[?25h")
-}
-
-func (t *terminal) HideCursor() {
-	t.WriteToConsole("\x1b[?25l")
-}
-
-func (t *terminal) EraseToEnd() {
-	t.WriteToConsole("\x1b[0K")
-}
-
-func (t *terminal) EraseToStart() {
-	t.WriteToConsole("\x1b[1K")
-}
-
-func (t *terminal) EraseLine() {
-	t.WriteToConsole("\x1b[2K")
-}
-
-func (t *terminal) InsertLine() {
-	t.WriteToConsole("\x1b[10L")
-}
-
-func (t *terminal) ScrollUp() {
-	t.WriteToConsole("\x1b[1S")
-}
+		return data[:i], append([]byte(nil), data[i:]...)
+	}
 
-func (t *terminal) ScrollDown() {
-	t.WriteToConsole("\x1b[1T")
+	return data, nil
 }
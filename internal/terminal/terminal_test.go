@@ -0,0 +1,30 @@
+package terminal
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerminalSplitRune(t *testing.T) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer r.Close()
+	defer w.Close()
+
+	term := &terminal{in: r.Fd()}
+	text := []byte("aé世🙂")
+
+	var runes []rune
+	for _, chunk := range [][]byte{text[:2], text[2:4], text[4:5], text[5:8], text[8:]} {
+		_, err = w.Write(chunk)
+		require.NoError(t, err)
+
+		rs, err := term.ReadRunes()
+		require.NoError(t, err)
+		runes = append(runes, rs...)
+	}
+
+	require.Equal(t, []rune("aé世🙂"), runes)
+}
@@ -0,0 +1,448 @@
+package terminal
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+var _ Terminal = NewVirtualTerminal(80, 24)
+
+// cellContinuation fills the second cell of a wide rune.
+const cellContinuation rune = -1
+
+// Cell is a single character place of the virtual screen.
+type Cell struct {
+	Rune  rune
+	Color Color
+}
+
+// virtualTerminal keeps the screen in memory instead of drawing it. The
+// escape sequences written by the shell are interpreted the same way a VT100
+// compatible terminal does, so the tests can check what a user would see.
+type virtualTerminal struct {
+	ansi
+	screen *screen
+
+	keys      chan []rune
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func NewVirtualTerminal(width, height int) *virtualTerminal {
+	t := &virtualTerminal{
+		screen: newScreen(width, height),
+		keys:   make(chan []rune, 64),
+		done:   make(chan struct{}),
+	}
+
+	t.out = t.screen
+	t.setSize(width, height)
+
+	return t
+}
+
+func (t *virtualTerminal) ResetTerminal() error {
+	return nil
+}
+
+// ReadRunes returns the next key passed to Input, io.EOF once the terminal
+// is closed.
+func (t *virtualTerminal) ReadRunes() ([]rune, error) {
+	select {
+	case key := <-t.keys:
+		return key, nil
+	case <-t.done:
+		return nil, io.EOF
+	}
+}
+
+// Input queues a key, an escape sequence is a single key.
+func (t *virtualTerminal) Input(key string) {
+	t.keys <- []rune(key)
+}
+
+func (t *virtualTerminal) Close() {
+	t.closeOnce.Do(func() {
+		close(t.done)
+	})
+}
+
+// Resize changes the size of the screen keeping its bottom left corner the
+// way terminals without reflow do.
+func (t *virtualTerminal) Resize(width, height int) {
+	t.screen.Resize(width, height)
+	t.setSize(width, height)
+}
+
+// Line returns the text of the row y counted from 1 without the trailing
+// spaces.
+func (t *virtualTerminal) Line(y int) string {
+	return t.screen.Line(y - 1)
+}
+
+// Screen returns the text of all the rows.
+func (t *virtualTerminal) Screen() []string {
+	lines := make([]string, t.Height())
+	for y := range lines {
+		lines[y] = t.screen.Line(y)
+	}
+
+	return lines
+}
+
+// Cell returns the character at the column x and the row y counted from 1.
+func (t *virtualTerminal) Cell(x, y int) Cell {
+	return t.screen.Cell(x-1, y-1)
+}
+
+// Cursor returns the cursor position counted from 1.
+func (t *virtualTerminal) Cursor() (x, y int) {
+	x, y = t.screen.Cursor()
+	return x + 1, y + 1
+}
+
+func (t *virtualTerminal) CursorVisible() bool {
+	return t.screen.CursorVisible()
+}
+
+type screenState int
+
+const (
+	screenStateText screenState = iota
+	screenStateEscape
+	screenStateCSI
+)
+
+// screen interprets the output of a terminal. A line feed returns the cursor
+// to the first column as the terminals translate it to CR LF by default.
+type screen struct {
+	mu sync.Mutex
+
+	width, height int
+	cells         [][]Cell
+	x, y          int
+	wrap          bool
+	color         Color
+	hidden        bool
+
+	state   screenState
+	params  []byte
+	pending []byte
+}
+
+func newScreen(width, height int) *screen {
+	s := &screen{
+		width:  width,
+		height: height,
+		color:  ColorWhite,
+	}
+
+	s.cells = make([][]Cell, height)
+	for y := range s.cells {
+		s.cells[y] = s.blankLine()
+	}
+
+	return s
+}
+
+func (s *screen) Resize(width, height int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cells := make([][]Cell, height)
+	shift := s.height - height
+
+	for y := range cells {
+		cells[y] = make([]Cell, width)
+		if y+shift >= 0 && y+shift < s.height {
+			copy(cells[y], s.cells[y+shift])
+		}
+	}
+
+	s.cells = cells
+	s.width, s.height = width, height
+	s.moveTo(s.x, s.y-shift)
+}
+
+func (s *screen) Line(y int) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if y < 0 || y >= s.height {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, c := range s.cells[y] {
+		switch c.Rune {
+		case cellContinuation:
+		case 0:
+			b.WriteRune(' ')
+		default:
+			b.WriteRune(c.Rune)
+		}
+	}
+
+	return strings.TrimRight(b.String(), " ")
+}
+
+func (s *screen) Cell(x, y int) Cell {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if x < 0 || x >= s.width || y < 0 || y >= s.height {
+		return Cell{}
+	}
+
+	return s.cells[y][x]
+}
+
+func (s *screen) Cursor() (x, y int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.x, s.y
+}
+
+func (s *screen) CursorVisible() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return !s.hidden
+}
+
+func (s *screen) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pending = append(s.pending, p...)
+
+	for len(s.pending) > 0 {
+		if !utf8.FullRune(s.pending) {
+			break
+		}
+
+		r, size := utf8.DecodeRune(s.pending)
+		s.pending = s.pending[size:]
+		s.handle(r)
+	}
+
+	return len(p), nil
+}
+
+func (s *screen) handle(r rune) {
+	switch s.state {
+	case screenStateText:
+		switch r {
+		case '\x1b':
+			s.state = screenStateEscape
+		case '\n':
+			s.lineFeed()
+			s.moveTo(0, s.y)
+		case '\r':
+			s.moveTo(0, s.y)
+		case '\b':
+			s.moveTo(s.x-1, s.y)
+		default:
+			if r >= ' ' {
+				s.print(r)
+			}
+		}
+	case screenStateEscape:
+		if r == '[' {
+			s.params = s.params[:0]
+			s.state = screenStateCSI
+		} else {
+			s.state = screenStateText
+		}
+	case screenStateCSI:
+		if r >= 0x40 && r <= 0x7e {
+			s.control(r, string(s.params))
+			s.state = screenStateText
+		} else {
+			s.params = append(s.params, byte(r))
+		}
+	}
+}
+
+func (s *screen) print(r rune) {
+	w := runewidth.RuneWidth(r)
+
+	if w == 0 {
+		return
+	}
+
+	// a wide rune which doesn't fit is moved to the next line
+	if s.wrap || (s.x > 0 && s.x+w > s.width) {
+		s.lineFeed()
+		s.x = 0
+		s.wrap = false
+	}
+
+	s.cells[s.y][s.x] = Cell{Rune: r, Color: s.color}
+	if w > 1 && s.x+1 < s.width {
+		s.cells[s.y][s.x+1] = Cell{Rune: cellContinuation, Color: s.color}
+	}
+
+	if s.x+w >= s.width {
+		s.x = s.width - 1
+		s.wrap = true
+	} else {
+		s.x += w
+	}
+}
+
+func (s *screen) control(final rune, params string) {
+	if strings.HasPrefix(params, "?") {
+		if params == "?25" {
+			s.hidden = final == 'l'
+		}
+		return
+	}
+
+	args := strings.Split(params, ";")
+	arg := func(i, def int) int {
+		if i >= len(args) {
+			return def
+		}
+
+		n, err := strconv.Atoi(args[i])
+		if err != nil || n == 0 {
+			return def
+		}
+
+		return n
+	}
+
+	switch final {
+	case 'H':
+		s.moveTo(arg(1, 1)-1, arg(0, 1)-1)
+	case 'G':
+		s.moveTo(arg(0, 1)-1, s.y)
+	case 'A':
+		s.moveTo(s.x, s.y-arg(0, 1))
+	case 'B':
+		s.moveTo(s.x, s.y+arg(0, 1))
+	case 'C':
+		s.moveTo(s.x+arg(0, 1), s.y)
+	case 'D':
+		s.moveTo(s.x-arg(0, 1), s.y)
+	case 'J':
+		s.eraseScreen(arg(0, 0))
+	case 'K':
+		s.eraseLine(arg(0, 0))
+	case 'L':
+		s.insertLines(arg(0, 1))
+	case 'S':
+		s.scrollUp(arg(0, 1))
+	case 'T':
+		s.scrollDown(arg(0, 1))
+	case 'm':
+		for i := range args {
+			switch n := arg(i, 0); {
+			case n >= 30 && n <= 37:
+				s.color = Color(n - 30)
+			case n == 0 || n == 39:
+				s.color = ColorWhite
+			}
+		}
+	}
+}
+
+func (s *screen) moveTo(x, y int) {
+	s.x = clamp(x, 0, s.width-1)
+	s.y = clamp(y, 0, s.height-1)
+	s.wrap = false
+}
+
+func (s *screen) lineFeed() {
+	if s.y == s.height-1 {
+		s.scrollUp(1)
+	} else {
+		s.y++
+	}
+}
+
+func (s *screen) eraseScreen(mode int) {
+	switch mode {
+	case 0:
+		s.eraseLine(0)
+		for y := s.y + 1; y < s.height; y++ {
+			s.cells[y] = s.blankLine()
+		}
+	case 1:
+		s.eraseLine(1)
+		for y := 0; y < s.y; y++ {
+			s.cells[y] = s.blankLine()
+		}
+	case 2:
+		for y := range s.cells {
+			s.cells[y] = s.blankLine()
+		}
+	}
+}
+
+func (s *screen) eraseLine(mode int) {
+	from, to := 0, s.width
+
+	switch mode {
+	case 0:
+		from = s.x
+	case 1:
+		to = s.x + 1
+	}
+
+	for x := from; x < to; x++ {
+		s.cells[s.y][x] = Cell{}
+	}
+}
+
+func (s *screen) insertLines(n int) {
+	n = clamp(n, 0, s.height-s.y)
+
+	lines := make([][]Cell, 0, s.height)
+	lines = append(lines, s.cells[:s.y]...)
+	for i := 0; i < n; i++ {
+		lines = append(lines, s.blankLine())
+	}
+	lines = append(lines, s.cells[s.y:s.height-n]...)
+
+	s.cells = lines
+}
+
+func (s *screen) scrollUp(n int) {
+	n = clamp(n, 0, s.height)
+
+	for i := 0; i < n; i++ {
+		s.cells = append(s.cells[1:], s.blankLine())
+	}
+}
+
+func (s *screen) scrollDown(n int) {
+	n = clamp(n, 0, s.height)
+
+	for i := 0; i < n; i++ {
+		s.cells = append([][]Cell{s.blankLine()}, s.cells[:s.height-1]...)
+	}
+}
+
+func (s *screen) blankLine() []Cell {
+	return make([]Cell, s.width)
+}
+
+func clamp(n, min, max int) int {
+	if n < min {
+		return min
+	}
+
+	if n > max {
+		return max
+	}
+
+	return n
+}
@@ -0,0 +1,85 @@
+package terminal
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVirtualTerminal(t *testing.T) {
+	term := NewVirtualTerminal(10, 3)
+
+	term.MoveCursorToPosition(0, 3)
+	term.SetColor(ColorGreen)
+	term.WriteToConsole("hello")
+	require.Equal(t, "hello", term.Line(3))
+	require.Equal(t, Cell{Rune: 'h', Color: ColorGreen}, term.Cell(1, 3))
+
+	x, y := term.Cursor()
+	require.Equal(t, 6, x)
+	require.Equal(t, 3, y)
+
+	term.MoveCursorTo(3)
+	term.EraseToEnd()
+	require.Equal(t, "he", term.Line(3))
+
+	// the text is wrapped and the screen is scrolled on the last line
+	term.WriteToConsole("0123456789ab")
+	require.Equal(t, []string{"", "he01234567", "89ab"}, term.Screen())
+
+	term.WriteToConsole("\nc")
+	require.Equal(t, []string{"he01234567", "89ab", "c"}, term.Screen())
+
+	term.MoveCursorToPosition(1, 1)
+	term.InsertLine()
+	require.Equal(t, []string{"", "", ""}, term.Screen())
+
+	term.HideCursor()
+	require.False(t, term.CursorVisible())
+	term.ShowCursor()
+	require.True(t, term.CursorVisible())
+}
+
+func TestVirtualTerminalInput(t *testing.T) {
+	term := NewVirtualTerminal(10, 3)
+
+	term.Input("\x1b[A")
+	rs, err := term.ReadRunes()
+	require.NoError(t, err)
+	require.Equal(t, []rune("\x1b[A"), rs)
+
+	term.Close()
+	_, err = term.ReadRunes()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestVirtualTerminalResize(t *testing.T) {
+	term := NewVirtualTerminal(5, 3)
+
+	term.MoveCursorToPosition(1, 2)
+	term.WriteToConsole("abcde\nfgh")
+
+	term.Resize(3, 2)
+	require.Equal(t, []string{"abc", "fgh"}, term.Screen())
+	require.Equal(t, 3, term.Width())
+
+	select {
+	case <-term.Resized():
+	default:
+		require.Fail(t, "resize is not notified")
+	}
+}
+
+func TestVirtualTerminalWideRunes(t *testing.T) {
+	term := NewVirtualTerminal(5, 2)
+
+	term.MoveCursorToPosition(1, 1)
+	term.WriteToConsole("aб世界")
+	require.Equal(t, []string{"aб世", "界"}, term.Screen())
+	require.Equal(t, Cell{Rune: '世', Color: ColorWhite}, term.Cell(3, 1))
+
+	x, y := term.Cursor()
+	require.Equal(t, 3, x)
+	require.Equal(t, 2, y)
+}
@@ -0,0 +1,440 @@
+// Package microshell builds an interactive RouterOS-like shell around a set of
+// user defined commands.
+//
+//	sh, err := microshell.NewBuilder().
+//		AddCommands(&microshell.Command{...}).
+//		SetPrompt("router", "admin").
+//		Build()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	sh.Run()
+package microshell
+
+import (
+	"io"
+
+	"github.com/blkmlk/microshell/internal/builtin"
+	"github.com/blkmlk/microshell/internal/catalog"
+	"github.com/blkmlk/microshell/internal/cursor"
+	"github.com/blkmlk/microshell/internal/history"
+	"github.com/blkmlk/microshell/internal/logger"
+	"github.com/blkmlk/microshell/internal/menu"
+	"github.com/blkmlk/microshell/internal/parser"
+	"github.com/blkmlk/microshell/internal/prompt"
+	"github.com/blkmlk/microshell/internal/script"
+	"github.com/blkmlk/microshell/internal/server"
+	"github.com/blkmlk/microshell/internal/shell"
+	"github.com/blkmlk/microshell/internal/terminal"
+	"github.com/sarulabs/di/v2"
+)
+
+type (
+	Shell          = shell.Shell
+	Runner         = script.Runner
+	Server         = server.Server
+	Terminal       = terminal.Terminal
+	Color          = terminal.Color
+	Command        = parser.Command
+	CommandType    = parser.CommandType
+	Flag           = parser.Flag
+	Flags          = parser.Flags
+	FlagValues     = parser.FlagValues
+	Options        = parser.Options
+	List           = parser.List
+	Value          = parser.Value
+	ValueKind      = parser.ValueKind
+	ValueType      = parser.ValueType
+	Context        = parser.Context
+	SystemContext  = parser.SystemContext
+	ExecFunc       = parser.ExecFunc
+	SystemExecFunc = parser.SystemExecFunc
+	OutFunc        = parser.OutFunc
+	Object         = parser.Object
+	Array          = parser.Array
+	ArrayItem      = parser.ArrayItem
+	ExecError      = parser.ExecError
+	Span           = parser.Span
+	UserError      = parser.UserError
+	SyntaxError    = parser.SyntaxError
+	Clock          = parser.Clock
+	Task           = parser.Task
+	Menu           = menu.Menu
+	Field          = menu.Field
+	Item           = menu.Item
+	Store          = menu.Store
+	Handlers       = catalog.Handlers
+	HistoryOptions = history.Options
+)
+
+const (
+	CommandTypeUser   = parser.CommandTypeUser
+	CommandTypeSystem = parser.CommandTypeSystem
+
+	ValueTypeString = parser.ValueTypeString
+	ValueTypeNumber = parser.ValueTypeNumber
+	ValueTypeBool   = parser.ValueTypeBool
+
+	ValueTypeIP        = parser.ValueTypeIP
+	ValueTypeIPPrefix  = parser.ValueTypeIPPrefix
+	ValueTypeIP6       = parser.ValueTypeIP6
+	ValueTypeIP6Prefix = parser.ValueTypeIP6Prefix
+	ValueTypeTime      = parser.ValueTypeTime
+
+	KindNothing   = parser.KindNothing
+	KindNil       = parser.KindNil
+	KindString    = parser.KindString
+	KindNumber    = parser.KindNumber
+	KindBool      = parser.KindBool
+	KindArray     = parser.KindArray
+	KindIP        = parser.KindIP
+	KindIPPrefix  = parser.KindIPPrefix
+	KindIP6       = parser.KindIP6
+	KindIP6Prefix = parser.KindIP6Prefix
+	KindTime      = parser.KindTime
+)
+
+const (
+	ColorBlack   = terminal.ColorBlack
+	ColorRed     = terminal.ColorRed
+	ColorGreen   = terminal.ColorGreen
+	ColorYellow  = terminal.ColorYellow
+	ColorBlue    = terminal.ColorBlue
+	ColorMagenta = terminal.ColorMagenta
+	ColorCyan    = terminal.ColorCyan
+	ColorWhite   = terminal.ColorWhite
+)
+
+const (
+	ObjectError             = parser.ObjectError
+	ObjectPath              = parser.ObjectPath
+	ObjectCommand           = parser.ObjectCommand
+	ObjectMandatoryFlag     = parser.ObjectMandatoryFlag
+	ObjectOptionalFlag      = parser.ObjectOptionalFlag
+	ObjectUnknown           = parser.ObjectUnknown
+	ObjectValue             = parser.ObjectValue
+	ObjectOption            = parser.ObjectOption
+	ObjectVariableName      = parser.ObjectVariableName
+	ObjectVariableWrongName = parser.ObjectVariableWrongName
+	ObjectQuotedString      = parser.ObjectQuotedString
+	ObjectComment           = parser.ObjectComment
+	ObjectEqualSymbol       = parser.ObjectEqualSymbol
+	ObjectVariableSymbol    = parser.ObjectVariableSymbol
+	ObjectQuotedSymbol      = parser.ObjectQuotedSymbol
+	ObjectOperator          = parser.ObjectOperator
+	ObjectSquareBrackets    = parser.ObjectSquareBrackets
+	ObjectRoundBrackets     = parser.ObjectRoundBrackets
+	ObjectCurlyBrackets     = parser.ObjectCurlyBrackets
+)
+
+var (
+	NullValue      = parser.NullValue
+	NilValue       = parser.NilValue
+	ParseValue     = parser.ParseValue
+	NewStringValue = parser.NewStringValue
+	NewNumberValue = parser.NewNumberValue
+	NewBoolValue   = parser.NewBoolValue
+
+	NewArrayValue          = parser.NewArrayValue
+	NewKeyedArrayValue     = parser.NewKeyedArrayValue
+	NewArrayValueFromItems = parser.NewArrayValueFromItems
+
+	NewIPValue       = parser.NewIPValue
+	NewIPPrefixValue = parser.NewIPPrefixValue
+	NewTimeValue     = parser.NewTimeValue
+	IP               = parser.IP
+	IPNet            = parser.IPNet
+	Duration         = parser.Duration
+
+	NewMenu        = menu.New
+	NewMemoryStore = menu.NewMemoryStore
+)
+
+// ErrServerClosed is returned by the server's Serve after Close.
+var ErrServerClosed = server.ErrServerClosed
+
+// ErrTimeout is the failure of a command which has run longer than its
+// Timeout.
+var ErrTimeout = parser.ErrTimeout
+
+// DefaultColors returns the highlighting scheme used when the builder is not
+// given one.
+func DefaultColors() map[Object]Color {
+	return map[Object]Color{
+		ObjectError:             ColorRed,
+		ObjectPath:              ColorBlue,
+		ObjectCommand:           ColorBlue,
+		ObjectOptionalFlag:      ColorYellow,
+		ObjectMandatoryFlag:     ColorYellow,
+		ObjectOption:            ColorMagenta,
+		ObjectValue:             ColorWhite,
+		ObjectEqualSymbol:       ColorCyan,
+		ObjectCurlyBrackets:     ColorYellow,
+		ObjectRoundBrackets:     ColorYellow,
+		ObjectSquareBrackets:    ColorYellow,
+		ObjectOperator:          ColorYellow,
+		ObjectQuotedSymbol:      ColorCyan,
+		ObjectQuotedString:      ColorCyan,
+		ObjectVariableSymbol:    ColorBlue,
+		ObjectVariableName:      ColorBlue,
+		ObjectVariableWrongName: ColorRed,
+	}
+}
+
+// LoadCatalog reads the YAML command catalogs at paths, binds their handler
+// names to handlers and returns the validated commands and their menus.
+func LoadCatalog(handlers Handlers, paths ...string) (List, error) {
+	c := catalog.New(handlers)
+
+	for _, path := range paths {
+		if err := c.LoadFile(path); err != nil {
+			return List{}, err
+		}
+	}
+
+	return c.List()
+}
+
+// Builder collects the commands and settings of a shell and wires them into
+// the dependency container on Build.
+type Builder struct {
+	commands []*Command
+	menus    []*parser.Menu
+	colors   map[Object]Color
+	hostname string
+	username string
+	history  []string
+	options  HistoryOptions
+	terminal Terminal
+	clock    Clock
+}
+
+// NewBuilder returns a builder preloaded with the system commands
+// (:global, :local, :put), the default colors and the default prompt.
+func NewBuilder() *Builder {
+	return &Builder{
+		commands: builtin.Commands(),
+		colors:   DefaultColors(),
+		hostname: "localhost",
+		username: "void",
+	}
+}
+
+// AddCommands registers user commands. Commands are validated on Build.
+func (b *Builder) AddCommands(commands ...*Command) *Builder {
+	b.commands = append(b.commands, commands...)
+	return b
+}
+
+// AddList registers the commands of the list along with the descriptions of
+// their menus.
+func (b *Builder) AddList(list List) *Builder {
+	b.commands = append(b.commands, list.Commands...)
+	b.menus = append(b.menus, list.Menus...)
+	return b
+}
+
+// AddMenus registers the commands of the menus.
+func (b *Builder) AddMenus(menus ...*Menu) *Builder {
+	for _, m := range menus {
+		b.commands = append(b.commands, m.Commands()...)
+	}
+
+	return b
+}
+
+// SetColors replaces the highlighting scheme.
+func (b *Builder) SetColors(colors map[Object]Color) *Builder {
+	b.colors = colors
+	return b
+}
+
+// SetPrompt sets the hostname and the username shown in the prompt.
+func (b *Builder) SetPrompt(hostname, username string) *Builder {
+	b.hostname = hostname
+	b.username = username
+	return b
+}
+
+// SetHistory preloads the history with the given lines, oldest first.
+func (b *Builder) SetHistory(values []string) *Builder {
+	b.history = values
+	return b
+}
+
+// SetHistoryOptions sets the file the history is kept in between the
+// sessions, its size limit and the lines left out of it. The entries of the
+// file replace the lines given to SetHistory. The sessions of BuildServer
+// don't use the file.
+func (b *Builder) SetHistoryOptions(options HistoryOptions) *Builder {
+	b.options = options
+	return b
+}
+
+// SetTerminal replaces the terminal attached to the standard input and output.
+func (b *Builder) SetTerminal(t Terminal) *Builder {
+	b.terminal = t
+	return b
+}
+
+// SetClock replaces the clock of the background jobs and the scheduler.
+func (b *Builder) SetClock(clock Clock) *Builder {
+	b.clock = clock
+	return b
+}
+
+// Build validates the command list and returns a shell ready to Run.
+func (b *Builder) Build() (*Shell, error) {
+	ctn, err := b.container()
+	if err != nil {
+		return nil, err
+	}
+
+	// the command list is validated while the root scope is built
+	if _, err = ctn.SafeGet(parser.DefinitionNameRootScope); err != nil {
+		return nil, err
+	}
+
+	return b.newShell(ctn, b.options)
+}
+
+// BuildServer validates the command list and returns a telnet server. Every
+// connection gets its own terminal, history, local variables and session
+// while the global variables and the commands are shared. The history of a
+// connection is kept in memory only: the sessions would overwrite each other
+// in a shared file.
+func (b *Builder) BuildServer() (*Server, error) {
+	ctn, err := b.container()
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := ctn.SafeGet(parser.DefinitionNameRootScope)
+	if err != nil {
+		return nil, err
+	}
+
+	rootCtx := root.(SystemContext)
+
+	options := b.options
+	options.File = ""
+
+	return server.New(func(term terminal.Terminal) (server.Session, error) {
+		ctn, err := b.container(
+			di.Def{
+				Name: terminal.DefinitionName,
+				Build: func(ctn di.Container) (interface{}, error) {
+					return term, nil
+				},
+			},
+			di.Def{
+				Name: parser.DefinitionNameRootScope,
+				Build: func(ctn di.Container) (interface{}, error) {
+					buffer := ctn.Get(terminal.DefinitionNameBuffer).(terminal.Buffer)
+					return rootCtx.New().WithBuffer(buffer).WithSession(parser.NewSession()), nil
+				},
+			},
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return b.newShell(ctn, options)
+	}), nil
+}
+
+func (b *Builder) newShell(ctn di.Container, options HistoryOptions) (*Shell, error) {
+	h := ctn.Get(history.DefinitionName).(history.History)
+
+	if len(b.history) > 0 {
+		h.Load(b.history)
+	}
+
+	if err := h.Open(options); err != nil {
+		return nil, err
+	}
+
+	sh := shell.NewShell(ctn)
+	sh.SetColors(b.colors)
+	sh.SetPrompt(b.hostname, b.username)
+
+	return sh, nil
+}
+
+// BuildRunner validates the command list and returns a runner executing
+// scripts without a terminal. The command output is written to out.
+func (b *Builder) BuildRunner(out io.Writer) (*Runner, error) {
+	ctn, err := b.container()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = ctn.SafeGet(parser.DefinitionNameRootScope); err != nil {
+		return nil, err
+	}
+
+	return script.NewRunner(ctn, out), nil
+}
+
+// container builds the dependency container, the definitions passed replace
+// the default ones.
+func (b *Builder) container(overrides ...di.Def) (di.Container, error) {
+	builder, err := di.NewBuilder()
+	if err != nil {
+		return nil, err
+	}
+
+	terminalDefinition := terminal.Definition
+	if b.terminal != nil {
+		terminalDefinition = di.Def{
+			Name: terminal.DefinitionName,
+			Build: func(ctn di.Container) (interface{}, error) {
+				return b.terminal, nil
+			},
+		}
+	}
+
+	clockDefinition := parser.DefinitionClock
+	if b.clock != nil {
+		clockDefinition = di.Def{
+			Name: parser.DefinitionNameClock,
+			Build: func(ctn di.Container) (interface{}, error) {
+				return b.clock, nil
+			},
+		}
+	}
+
+	commands := append([]*Command{}, b.commands...)
+	menus := append([]*parser.Menu{}, b.menus...)
+
+	err = builder.Add(
+		terminalDefinition,
+		cursor.Definition,
+		prompt.Definition,
+		history.Definition,
+		logger.Definition,
+		terminal.DefinitionBuffer,
+
+		parser.Definition,
+		parser.DefinitionScope,
+		parser.DefinitionContext,
+		clockDefinition,
+		di.Def{
+			Name: parser.DefinitionNameCommandTree,
+			Build: func(ctn di.Container) (interface{}, error) {
+				return parser.List{Commands: commands, Menus: menus}, nil
+			},
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = builder.Add(overrides...); err != nil {
+		return nil, err
+	}
+
+	return builder.Build(), nil
+}
@@ -0,0 +1,64 @@
+package microshell
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/blkmlk/microshell/internal/terminal"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilderValidatesCommands(t *testing.T) {
+	_, err := NewBuilder().
+		AddCommands(&Command{
+			Type: CommandTypeUser,
+			Path: []string{"ip", "firewall"},
+			Name: "add",
+			Flags: map[string]*Flag{
+				"network": {
+					Name:      "network",
+					Mandatory: false,
+					Number:    1,
+					ValueType: ValueTypeString,
+				},
+			},
+		}).
+		Build()
+
+	require.Error(t, err)
+}
+
+func TestLoadCatalog(t *testing.T) {
+	exec := func(ctx Context, flags FlagValues, options Options) (Value, error) {
+		return NullValue, nil
+	}
+
+	list, err := LoadCatalog(Handlers{
+		"firewall-add":   exec,
+		"firewall-print": exec,
+		"reboot":         exec,
+	}, "internal/catalog/testdata/firewall.yaml")
+
+	require.NoError(t, err)
+	require.Len(t, list.Commands, 3)
+	require.Len(t, list.Menus, 2)
+
+	_, err = NewBuilder().AddList(list).Build()
+	require.NoError(t, err)
+
+	_, err = LoadCatalog(nil, "internal/catalog/testdata/firewall.yaml")
+	require.Error(t, err)
+}
+
+func TestLineTerminalShell(t *testing.T) {
+	out := new(bytes.Buffer)
+	in := strings.NewReader(":global a 4\n\n:put ($a * 2)\n:put (1 +\n:put done\n")
+
+	sh, err := NewBuilder().SetTerminal(terminal.NewLineTerminal(in, out)).Build()
+	require.NoError(t, err)
+
+	sh.Run()
+	require.Equal(t, "8\nsyntax error: unclosed `(` (line 1 column 10)\ndone\n", out.String())
+}
@@ -0,0 +1,143 @@
+package microshell
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type telnetClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func dialTelnet(t *testing.T, addr string) *telnetClient {
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+
+	return &telnetClient{t: t, conn: conn, r: bufio.NewReader(conn)}
+}
+
+func (c *telnetClient) send(s string) {
+	_, err := c.conn.Write([]byte(s))
+	require.NoError(c.t, err)
+}
+
+// readUntil reads the output until it contains s.
+func (c *telnetClient) readUntil(s string) string {
+	require.NoError(c.t, c.conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	var out strings.Builder
+	for !strings.Contains(out.String(), s) {
+		b, err := c.r.ReadByte()
+		require.NoError(c.t, err, out.String())
+		out.WriteByte(b)
+	}
+
+	return out.String()
+}
+
+func TestServerSharesGlobals(t *testing.T) {
+	srv, err := NewBuilder().BuildServer()
+	require.NoError(t, err)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- srv.Serve(l)
+	}()
+
+	first := dialTelnet(t, l.Addr().String())
+	second := dialTelnet(t, l.Addr().String())
+
+	first.readUntil("] >")
+	first.send(":global a 5\r\n")
+	first.readUntil("] >")
+
+	second.readUntil("] >")
+	second.send(":put ($a * 2)\r\n")
+	second.readUntil("m10\x1b")
+
+	require.NoError(t, srv.Close())
+	require.Equal(t, ErrServerClosed, <-done)
+}
+
+func TestServerHistoryInMemory(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "history")
+
+	srv, err := NewBuilder().SetHistoryOptions(HistoryOptions{File: file}).BuildServer()
+	require.NoError(t, err)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- srv.Serve(l)
+	}()
+
+	client := dialTelnet(t, l.Addr().String())
+	client.readUntil("] >")
+	client.send(":put 1\r\n")
+	client.readUntil("] >")
+
+	// the entry is still in the history of the session
+	client.send("\x1b[A")
+	client.readUntil(":put 1")
+
+	require.NoError(t, srv.Close())
+	require.Equal(t, ErrServerClosed, <-done)
+
+	_, err = os.Stat(file)
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestServerCancelsOnDisconnect(t *testing.T) {
+	started := make(chan struct{})
+	stopped := make(chan struct{})
+
+	srv, err := NewBuilder().AddCommands(&Command{
+		Type: CommandTypeUser,
+		Name: "wait",
+		ExecFunc: func(ctx Context, flags FlagValues, options Options) (Value, error) {
+			close(started)
+			<-ctx.Done()
+			close(stopped)
+			return nil, ctx.Err()
+		},
+	}).BuildServer()
+	require.NoError(t, err)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- srv.Serve(l)
+	}()
+
+	client := dialTelnet(t, l.Addr().String())
+	client.readUntil("] >")
+	client.send("/wait\r\n")
+	<-started
+
+	require.NoError(t, client.conn.Close())
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the command runs after the client has left")
+	}
+
+	require.NoError(t, srv.Close())
+	require.Equal(t, ErrServerClosed, <-done)
+}